@@ -0,0 +1,52 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/har"
+	"io"
+	"os"
+)
+
+// runConvert converts between har and NDJSON streaming the entries, so that it can be used on files of any size.
+func runConvert(fs *flag.FlagSet, args []string, stdin io.Reader, stdout io.Writer) error {
+	to := fs.String("to", "ndjson", "target format: ndjson or har")
+	outFile := fs.String("o", "", "output file (default stdout)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() > 1 {
+		return errors.New("at most one input is allowed")
+	}
+
+	r := stdin
+	if fs.NArg() == 1 && fs.Arg(0) != stdinArg {
+		f, err := os.Open(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	w := stdout
+	if *outFile != "" {
+		f, err := os.Create(*outFile)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	switch *to {
+	case "ndjson":
+		return har.ConvertHARToNDJSON(r, w)
+	case "har":
+		return har.ConvertNDJSONToHAR(r, w, har.NewHAR(har.WithCreator("har-cli", "1.0")).Log)
+	}
+
+	return fmt.Errorf("unknown format %s: wanted one of ndjson, har", *to)
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/har"
+	"io"
+)
+
+func runCurl(fs *flag.FlagSet, args []string, stdin io.Reader, stdout io.Writer) error {
+	ndx := fs.Int("i", -1, "index of the entry as reported by 'har list' (default all the entries)")
+	expr := fs.String("e", "", "filter expression (see 'har filter -h')")
+	multiline := fs.Bool("multiline", false, "one curl option per line")
+	insecure := fs.Bool("k", false, "add the -k flag to the commands")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	entries, _, err := loadEntries(fs.Args(), stdin)
+	if err != nil {
+		return err
+	}
+
+	if *ndx >= 0 {
+		if *ndx >= len(entries) {
+			return fmt.Errorf("entry index %d out of range [0, %d)", *ndx, len(entries))
+		}
+		entries = entries[*ndx : *ndx+1]
+	}
+
+	entries, err = filterEntries(entries, *expr)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		if c := e.Curl(har.CurlMultiline(*multiline), har.CurlInsecure(*insecure)); c != "" {
+			fmt.Fprintln(stdout, c)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/har"
+	"io"
+	"strings"
+)
+
+func runDiff(fs *flag.FlagSet, args []string, stdin io.Reader, stdout io.Writer) error {
+	align := fs.String("align", "order", "entry alignment: order, template (method + url template) or trace (trace id)")
+	ignoreHeaders := fs.String("ignore-headers", "date,content-length", "comma separated headers to ignore")
+	ignoreFields := fs.String("ignore-fields", "", "comma separated json fields to ignore (dot paths, * wildcard)")
+	requests := fs.Bool("requests", false, "compare request headers and bodies too")
+	asJson := fs.Bool("json", false, "print the differences as json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 2 {
+		return errors.New("two har files are required")
+	}
+
+	a, err := har.ParseAlignment(*align)
+	if err != nil {
+		return err
+	}
+
+	left, err := loadInputs(fs.Args()[:1], stdin)
+	if err != nil {
+		return err
+	}
+
+	right, err := loadInputs(fs.Args()[1:], stdin)
+	if err != nil {
+		return err
+	}
+
+	d := left[0].har.Log.Diff(right[0].har.Log,
+		har.WithAlignment(a),
+		har.IgnoreHeaders(splitList(*ignoreHeaders)...),
+		har.IgnoreJsonFields(splitList(*ignoreFields)...),
+		har.CompareRequests(*requests),
+	)
+
+	if *asJson {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(d)
+	}
+
+	return d.Write(stdout)
+}
+
+func splitList(s string) []string {
+	var res []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			res = append(res, item)
+		}
+	}
+	return res
+}
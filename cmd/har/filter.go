@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/har"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/hartracing"
+	"github.com/PaesslerAG/gval"
+	"io"
+	"net/url"
+	"strings"
+)
+
+const filterExprHelp = `boolean expression over the entry fields:
+method, url, host, path, query, status, statusText, time, started, traceId, logId, comment,
+mimeType, reqBodySize, respBodySize, reqBody, respBody, reqHeaders["name"], respHeaders["name"] (header names are case-insensitive)
+e.g. method == "POST" && status >= 500 && url =~ "/api/v1/"`
+
+type entryFilter func(e *har.Entry) (bool, error)
+
+func compileFilter(expr string) (entryFilter, error) {
+	if strings.TrimSpace(expr) == "" {
+		return func(e *har.Entry) (bool, error) { return true, nil }, nil
+	}
+
+	eval, err := gval.Full().NewEvaluable(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(e *har.Entry) (bool, error) {
+		return eval.EvalBool(context.Background(), entryParams(e))
+	}, nil
+}
+
+func filterEntries(entries []*har.Entry, expr string) ([]*har.Entry, error) {
+	f, err := compileFilter(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []*har.Entry
+	for i, e := range entries {
+		ok, err := f(e)
+		if err != nil {
+			return nil, fmt.Errorf("entry #%d: %w", i, err)
+		}
+		if ok {
+			res = append(res, e)
+		}
+	}
+	return res, nil
+}
+
+func entryParams(e *har.Entry) map[string]interface{} {
+	p := map[string]interface{}{
+		"started":      e.StartedDateTime,
+		"time":         e.Time,
+		"traceId":      e.TraceId,
+		"logId":        logIdOf(e.TraceId),
+		"comment":      e.Comment,
+		"method":       "",
+		"url":          "",
+		"host":         "",
+		"path":         "",
+		"query":        "",
+		"reqBodySize":  int64(-1),
+		"reqBody":      "",
+		"reqHeaders":   headersParam{},
+		"status":       0,
+		"statusText":   "",
+		"mimeType":     "",
+		"respBodySize": int64(-1),
+		"respBody":     "",
+		"respHeaders":  headersParam{},
+	}
+
+	if req := e.Request; req != nil {
+		p["method"] = req.Method
+		p["url"] = req.URL
+		if u, err := url.Parse(req.URL); err == nil {
+			p["host"] = u.Host
+			p["path"] = u.Path
+			p["query"] = u.RawQuery
+		}
+		p["reqBodySize"] = req.BodySize
+		if req.PostData != nil {
+			p["reqBody"] = string(req.PostData.Body())
+		}
+		p["reqHeaders"] = newHeadersParam(req.Headers)
+	}
+
+	if resp := e.Response; resp != nil {
+		p["status"] = resp.Status
+		p["statusText"] = resp.StatusText
+		p["respBodySize"] = resp.BodySize
+		if resp.Content != nil {
+			p["mimeType"] = resp.Content.MimeType
+			p["respBody"] = string(resp.Content.Body())
+		}
+		p["respHeaders"] = newHeadersParam(resp.Headers)
+	}
+
+	return p
+}
+
+// headersParam exposes the headers to the expressions, keyed by lowercase name and looked up case-insensitively.
+type headersParam map[string]interface{}
+
+func newHeadersParam(nvs har.NameValuePairs) headersParam {
+	m := make(headersParam, len(nvs))
+	for _, nv := range nvs {
+		m[strings.ToLower(nv.Name)] = nv.Value
+	}
+	return m
+}
+
+// SelectGVal implements gval.Selector: a missing header evaluates to nil.
+func (h headersParam) SelectGVal(_ context.Context, key string) (interface{}, error) {
+	return h[strings.ToLower(key)], nil
+}
+
+// logIdOf returns the log id of a span id, the id shared by all the spans of a trace. Unparsable ids are returned as they are.
+func logIdOf(traceId string) string {
+	sctx, err := hartracing.ExtractSimpleSpanContextFromString(traceId)
+	if err != nil {
+		return traceId
+	}
+	return sctx.LogId
+}
+
+func runFilter(fs *flag.FlagSet, args []string, stdin io.Reader, stdout io.Writer) error {
+	expr := fs.String("e", "", filterExprHelp)
+	outFile := fs.String("o", "", "output file (default stdout)")
+	indent := fs.Bool("indent", false, "indent the output")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	entries, inputs, err := loadEntries(fs.Args(), stdin)
+	if err != nil {
+		return err
+	}
+
+	entries, err = filterEntries(entries, *expr)
+	if err != nil {
+		return err
+	}
+
+	return writeOutput(newOutputHAR(inputs, entries), *outFile, *indent, stdout)
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/har"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const stdinArg = "-"
+
+type input struct {
+	name string
+	har  *har.HAR
+}
+
+// loadInputs reads the har documents named by the args: files, folders (every *.har file) or stdin.
+func loadInputs(args []string, stdin io.Reader) ([]input, error) {
+	if len(args) == 0 {
+		args = []string{stdinArg}
+	}
+
+	var inputs []input
+	for _, a := range args {
+		if a == stdinArg {
+			h, err := har.ReadHAR(stdin)
+			if err != nil {
+				return nil, err
+			}
+			inputs = append(inputs, input{name: "stdin", har: h})
+			continue
+		}
+
+		fi, err := os.Stat(a)
+		if err != nil {
+			return nil, err
+		}
+
+		files := []string{a}
+		if fi.IsDir() {
+			files, err = harFilesInFolder(a)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		for _, fn := range files {
+			h, err := loadFile(fn)
+			if err != nil {
+				return nil, err
+			}
+			inputs = append(inputs, input{name: fn, har: h})
+		}
+	}
+
+	return inputs, nil
+}
+
+// loadFile reads a har document or, if the extension is .ndjson or .jsonl, entries in NDJSON format.
+func loadFile(fn string) (*har.HAR, error) {
+	if !isNDJSONFile(fn) {
+		return har.LoadFile(fn)
+	}
+
+	f, err := os.Open(fn)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return har.ReadNDJSON(f)
+}
+
+func isNDJSONFile(fn string) bool {
+	ext := strings.ToLower(filepath.Ext(fn))
+	return ext == ".ndjson" || ext == ".jsonl"
+}
+
+func harFilesInFolder(folder string) ([]string, error) {
+	des, err := os.ReadDir(folder)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, de := range des {
+		if !de.IsDir() && strings.HasSuffix(strings.ToLower(de.Name()), ".har") {
+			files = append(files, filepath.Join(folder, de.Name()))
+		}
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// loadEntries returns the entries of all the inputs in order.
+func loadEntries(args []string, stdin io.Reader) ([]*har.Entry, []input, error) {
+	inputs, err := loadInputs(args, stdin)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var entries []*har.Entry
+	for _, in := range inputs {
+		entries = append(entries, in.har.Log.Entries...)
+	}
+
+	return entries, inputs, nil
+}
+
+// newOutputHAR creates a har carrying the header fields of the first input.
+func newOutputHAR(inputs []input, entries []*har.Entry) *har.HAR {
+	h := har.NewHAR(har.WithCreator("har-cli", "1.0"))
+	if len(inputs) > 0 && inputs[0].har.Log != nil {
+		l := inputs[0].har.Log
+		if l.Version != "" {
+			h.Log.Version = l.Version
+		}
+		if l.Creator != nil {
+			h.Log.Creator = l.Creator
+		}
+		h.Log.Browser = l.Browser
+		h.Log.Comment = l.Comment
+	}
+
+	h.Log.Entries = entries
+	if h.Log.Entries == nil {
+		h.Log.Entries = []*har.Entry{}
+	}
+	return h
+}
+
+func writeOutput(h *har.HAR, outFile string, indent bool, stdout io.Writer) error {
+	if outFile == "" || outFile == stdinArg {
+		return h.Write(stdout, indent)
+	}
+	return h.WriteFile(outFile, indent)
+}
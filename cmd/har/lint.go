@@ -0,0 +1,62 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/har"
+	"io"
+)
+
+// runLint reports the violations of the HAR 1.2 specification and fails if errors are left unfixed.
+func runLint(fs *flag.FlagSet, args []string, stdin io.Reader, stdout io.Writer) error {
+	fix := fs.Bool("fix", false, "fix the fixable issues and write the fixed document (single input only)")
+	outFile := fs.String("o", "", "output file of the fixed document (default stdout)")
+	indent := fs.Bool("indent", false, "indent the output")
+	warnings := fs.Bool("warnings", true, "report the warnings too")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	inputs, err := loadInputs(fs.Args(), stdin)
+	if err != nil {
+		return err
+	}
+
+	if *fix && len(inputs) != 1 {
+		return errors.New("-fix requires a single input")
+	}
+
+	// the fixed document goes on stdout: the report is written only if an output file is set.
+	report := stdout
+	if *fix && *outFile == "" {
+		report = io.Discard
+	}
+
+	errs := 0
+	for _, in := range inputs {
+		for _, issue := range in.har.Validate(har.WithAutoFix(*fix)) {
+			if issue.Severity == har.SeverityError && !issue.Fixed {
+				errs++
+			}
+
+			if issue.Severity == har.SeverityWarning && !*warnings {
+				continue
+			}
+
+			fmt.Fprintf(report, "%s: %s\n", in.name, issue.String())
+		}
+	}
+
+	if *fix {
+		if err = writeOutput(inputs[0].har, *outFile, *indent, stdout); err != nil {
+			return err
+		}
+	}
+
+	if errs > 0 {
+		return fmt.Errorf("%d errors found", errs)
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/har"
+	"io"
+	"text/tabwriter"
+)
+
+func runList(fs *flag.FlagSet, args []string, stdin io.Reader, stdout io.Writer) error {
+	expr := fs.String("e", "", "filter expression (see 'har filter -h')")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	entries, _, err := loadEntries(fs.Args(), stdin)
+	if err != nil {
+		return err
+	}
+
+	f, err := compileFilter(*expr)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "#\tTIME\tMETHOD\tURL\tSTATUS\tDURATION\tTRACE-ID")
+	for i, e := range entries {
+		ok, err := f(e)
+		if err != nil {
+			return fmt.Errorf("entry #%d: %w", i, err)
+		}
+
+		if ok {
+			method, u, status := entrySummary(e)
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%.0fms\t%s\n", i, e.StartedDateTime, method, u, status, e.Time, e.TraceId)
+		}
+	}
+
+	return tw.Flush()
+}
+
+func entrySummary(e *har.Entry) (string, string, string) {
+	var method, u, status string
+	if e.Request != nil {
+		method = e.Request.Method
+		u = e.Request.URL
+	}
+
+	status = "-"
+	if e.Response != nil {
+		status = fmt.Sprint(e.Response.Status)
+	}
+
+	return method, u, status
+}
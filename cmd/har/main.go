@@ -0,0 +1,79 @@
+// Command har inspects and manipulates the har files produced by the har tracers.
+//
+// Usage:
+//
+//	har <command> [flags] [file|folder|-]...
+//
+// Files and folders (all the *.har files it contains) are read in order; with no arguments or "-" the har is read from stdin.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+type command struct {
+	name  string
+	usage string
+	run   func(fs *flag.FlagSet, args []string, stdin io.Reader, stdout io.Writer) error
+}
+
+var commands = []command{
+	{name: "list", usage: "list the entries as a table", run: runList},
+	{name: "show", usage: "show one entry with pretty-printed bodies", run: runShow},
+	{name: "filter", usage: "write a har with the entries matching an expression", run: runFilter},
+	{name: "merge", usage: "merge the inputs into a single har", run: runMerge},
+	{name: "split", usage: "split the inputs in one har per trace id", run: runSplit},
+	{name: "stats", usage: "compute statistics over the entries", run: runStats},
+	{name: "convert", usage: "convert between har and ndjson (one entry per line)", run: runConvert},
+	{name: "lint", usage: "check the inputs against the har 1.2 specification", run: runLint},
+	{name: "diff", usage: "report the differences between two har files", run: runDiff},
+	{name: "curl", usage: "print the curl commands reproducing the requests", run: runCurl},
+	{name: "postman", usage: "export the entries as a postman v2.1 collection", run: runPostman},
+	{name: "openapi", usage: "infer an openapi 3 document from the entries", run: runOpenApi},
+	{name: "validate", usage: "validate the entries against an openapi 3 document", run: runValidate},
+	{name: "testgen", usage: "generate a go httptest regression test replaying the entries", run: runTestGen},
+	{name: "replay", usage: "re-send the entries against a target service and record the exchanges", run: runReplay},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "help" {
+		usage(stderr)
+		return 2
+	}
+
+	for _, c := range commands {
+		if c.name == args[0] {
+			fs := flag.NewFlagSet(c.name, flag.ContinueOnError)
+			fs.SetOutput(stderr)
+			if err := c.run(fs, args[1:], stdin, stdout); err != nil {
+				if err != flag.ErrHelp {
+					fmt.Fprintf(stderr, "har %s: %v\n", c.name, err)
+				}
+				return 1
+			}
+			return 0
+		}
+	}
+
+	fmt.Fprintf(stderr, "har: unknown command %q\n", args[0])
+	usage(stderr)
+	return 2
+}
+
+func usage(w io.Writer) {
+	var sb strings.Builder
+	sb.WriteString("usage: har <command> [flags] [file|folder|-]...\n\ncommands:\n")
+	for _, c := range commands {
+		sb.WriteString(fmt.Sprintf("  %-8s %s\n", c.name, c.usage))
+	}
+	sb.WriteString("\nrun 'har <command> -h' for the flags of a command\n")
+	fmt.Fprint(w, sb.String())
+}
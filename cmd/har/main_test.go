@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bytes"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/har"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testHAR(t *testing.T) []byte {
+	req1, err := har.NewRequest(http.MethodPost, "http://localhost:3004/api/v1/orc-01", []byte(`{"canale":"APPP"}`), http.Header{"Content-Type": []string{"application/json"}}, nil, nil)
+	require.NoError(t, err)
+	req2, err := har.NewRequest(http.MethodGet, "http://localhost:3004/api/v1/endpoint-01/10724279", nil, http.Header{}, nil, nil)
+	require.NoError(t, err)
+
+	h := har.NewHAR(
+		har.WithEntry(&har.Entry{
+			StartedDateTime: "2023-02-12T20:07:02.147874+01:00",
+			Time:            5,
+			Request:         req1,
+			Response:        har.NewResponse(200, "OK", "application/json", []byte(`{"esito":"OK"}`), nil),
+			TraceId:         "log-01:log-01:span-01:1",
+		}),
+		har.WithEntry(&har.Entry{
+			StartedDateTime: "2023-02-12T20:07:02.149311+01:00",
+			Time:            3,
+			Request:         req2,
+			Response:        har.NewResponse(503, "Service Unavailable", "application/json", nil, nil),
+			TraceId:         "log-02:log-02:span-02:1",
+		}),
+	)
+
+	var buf bytes.Buffer
+	require.NoError(t, h.Write(&buf, false))
+	return buf.Bytes()
+}
+
+func runCmd(t *testing.T, stdin []byte, args ...string) string {
+	var stdout, stderr bytes.Buffer
+	rc := run(args, bytes.NewReader(stdin), &stdout, &stderr)
+	require.Equal(t, 0, rc, stderr.String())
+	return stdout.String()
+}
+
+func TestList(t *testing.T) {
+	out := runCmd(t, testHAR(t), "list", "-e", `status >= 500`)
+	t.Log("\n" + out)
+	require.Contains(t, out, "endpoint-01/10724279")
+	require.NotContains(t, out, "orc-01")
+}
+
+func TestShow(t *testing.T) {
+	out := runCmd(t, testHAR(t), "show", "-i", "0")
+	t.Log("\n" + out)
+	require.Contains(t, out, "> POST http://localhost:3004/api/v1/orc-01")
+	require.Contains(t, out, `"canale": "APPP"`)
+}
+
+func TestFilterMergeSplit(t *testing.T) {
+	src := testHAR(t)
+
+	filtered := runCmd(t, src, "filter", "-e", `method == "POST" && reqHeaders["content-type"] =~ "json"`)
+	h, err := har.ReadHAR(strings.NewReader(filtered))
+	require.NoError(t, err)
+	require.Len(t, h.Log.Entries, 1)
+
+	filtered = runCmd(t, src, "filter", "-e", `reqHeaders["Content-Type"] == "application/json" && respHeaders["X-Missing"] == nil`)
+	h, err = har.ReadHAR(strings.NewReader(filtered))
+	require.NoError(t, err)
+	require.Len(t, h.Log.Entries, 1, "header names are case-insensitive")
+
+	dir := t.TempDir()
+	fn := filepath.Join(dir, "one.har")
+	require.NoError(t, os.WriteFile(fn, src, os.ModePerm))
+	merged := runCmd(t, nil, "merge", fn, fn)
+	h, err = har.ReadHAR(strings.NewReader(merged))
+	require.NoError(t, err)
+	require.Len(t, h.Log.Entries, 4)
+
+	out := filepath.Join(dir, "split")
+	runCmd(t, src, "split", "-o", out)
+	files, err := harFilesInFolder(out)
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+}
+
+func TestStats(t *testing.T) {
+	st := computeStats(mustEntries(t, testHAR(t)))
+	require.Equal(t, 2, st.Entries)
+	require.Equal(t, 2, st.Traces)
+	require.Equal(t, 1, st.ByStatus["5xx"])
+	require.Equal(t, float64(5), st.MaxTime)
+
+	out := runCmd(t, testHAR(t), "stats")
+	t.Log("\n" + out)
+}
+
+func mustEntries(t *testing.T, b []byte) []*har.Entry {
+	h, err := har.ReadHAR(bytes.NewReader(b))
+	require.NoError(t, err)
+	return h.Log.Entries
+}
+
+func TestPostman(t *testing.T) {
+	out := runCmd(t, testHAR(t), "postman", "-group", "trace", "-name", "orc")
+	require.Contains(t, out, `"name": "log-01"`)
+	require.Contains(t, out, `"raw": "{{baseUrl}}/api/v1/orc-01"`)
+}
+
+func TestOpenApi(t *testing.T) {
+	out := runCmd(t, testHAR(t), "openapi", "-format", "json")
+	require.Contains(t, out, `"/api/v1/endpoint-01/{id}"`)
+	require.Contains(t, out, `"operationId": "postApiV1Orc01"`)
+}
+
+func TestValidate(t *testing.T) {
+	src := testHAR(t)
+
+	dir := t.TempDir()
+	spec := filepath.Join(dir, "spec.yaml")
+	runCmd(t, src, "openapi", "-o", spec)
+	out := runCmd(t, src, "validate", "-spec", spec)
+	require.Contains(t, out, "2 entries, 0 violations")
+
+	var stdout, stderr bytes.Buffer
+	rc := run([]string{"validate", "-spec", spec, "-e", `method == "GET"`, "-"}, bytes.NewReader(src), &stdout, &stderr)
+	require.Equal(t, 0, rc, stderr.String())
+
+	h, err := har.ReadHAR(bytes.NewReader(src))
+	require.NoError(t, err)
+	h.Log.Entries[1].Response.Status = 418
+	var buf bytes.Buffer
+	require.NoError(t, h.Write(&buf, false))
+
+	stdout.Reset()
+	rc = run([]string{"validate", "-spec", spec}, &buf, &stdout, &stderr)
+	require.Equal(t, 1, rc)
+	require.Contains(t, stdout.String(), "undocumented-status")
+}
+
+func TestTestGen(t *testing.T) {
+	out := runCmd(t, testHAR(t), "testgen", "-package", "api_test", "-imports", "github.com/acme/orders/api", "-handler", "api.NewHandler()", "-exclude", "esito")
+	require.Contains(t, out, "handler := api.NewHandler()")
+	require.Contains(t, out, `"http://localhost:3004/api/v1/endpoint-01/10724279"`)
+	require.NotContains(t, out, `"esito"`)
+}
+
+func TestConvert(t *testing.T) {
+	lines := runCmd(t, testHAR(t), "convert", "-to", "ndjson")
+	require.Len(t, strings.Split(strings.TrimSpace(lines), "\n"), 2)
+
+	fn := filepath.Join(t.TempDir(), "entries.ndjson")
+	require.NoError(t, os.WriteFile(fn, []byte(lines), os.ModePerm))
+	out := runCmd(t, nil, "list", fn)
+	require.Contains(t, out, "orc-01")
+
+	h, err := har.ReadHAR(strings.NewReader(runCmd(t, []byte(lines), "convert", "-to", "har")))
+	require.NoError(t, err)
+	require.Len(t, h.Log.Entries, 2)
+	require.Equal(t, "log-01:log-01:span-01:1", h.Log.Entries[0].TraceId)
+}
+
+func TestLint(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	rc := run([]string{"lint"}, bytes.NewReader(testHAR(t)), &stdout, &stderr)
+	require.Equal(t, 1, rc)
+	require.Contains(t, stdout.String(), "log.entries[0].cache")
+
+	fixed := runCmd(t, testHAR(t), "lint", "-fix")
+	out := runCmd(t, []byte(fixed), "lint", "-warnings=false")
+	require.Empty(t, out)
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"flag"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/har"
+	"io"
+)
+
+func runMerge(fs *flag.FlagSet, args []string, stdin io.Reader, stdout io.Writer) error {
+	outFile := fs.String("o", "", "output file (default stdout)")
+	indent := fs.Bool("indent", false, "indent the output")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	inputs, err := loadInputs(fs.Args(), stdin)
+	if err != nil {
+		return err
+	}
+
+	merged := newOutputHAR(inputs, nil)
+	for _, in := range inputs {
+		merged, err = merged.Merge(in.har, compareStartedDateTime)
+		if err != nil {
+			return err
+		}
+	}
+
+	return writeOutput(merged, *outFile, *indent, stdout)
+}
+
+func compareStartedDateTime(e1, e2 *har.Entry) bool {
+	return e1.StartedDateTime < e2.StartedDateTime
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"flag"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/har"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/haropenapi"
+	"io"
+)
+
+func runOpenApi(fs *flag.FlagSet, args []string, stdin io.Reader, stdout io.Writer) error {
+	expr := fs.String("e", "", "filter expression (see 'har filter -h')")
+	title := fs.String("title", "har", "title of the api")
+	version := fs.String("version", "1.0.0", "version of the api")
+	format := fs.String("format", haropenapi.FormatYAML, "output format: yaml or json")
+	examples := fs.Bool("examples", true, "add the recorded values as examples")
+	outFile := fs.String("o", "", "output file, the format is taken from the extension (default stdout)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	entries, _, err := loadEntries(fs.Args(), stdin)
+	if err != nil {
+		return err
+	}
+
+	entries, err = filterEntries(entries, *expr)
+	if err != nil {
+		return err
+	}
+
+	doc, err := haropenapi.Generate(
+		&har.HAR{Log: &har.Log{Entries: entries}},
+		haropenapi.WithTitle(*title), haropenapi.WithVersion(*version), haropenapi.WithExamples(*examples))
+	if err != nil {
+		return err
+	}
+
+	if *outFile != "" {
+		return doc.WriteFile(*outFile)
+	}
+
+	return doc.Write(stdout, *format)
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/har"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/harpostman"
+	"io"
+	"os"
+)
+
+func runPostman(fs *flag.FlagSet, args []string, stdin io.Reader, stdout io.Writer) error {
+	expr := fs.String("e", "", "filter expression (see 'har filter -h')")
+	name := fs.String("name", "har", "name of the collection")
+	group := fs.String("group", "host", "folders of the collection: host, trace or none")
+	examples := fs.Bool("examples", true, "save the recorded responses as examples")
+	outFile := fs.String("o", "", "output file (default stdout)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	grouping, err := harpostman.ParseGrouping(*group)
+	if err != nil {
+		return err
+	}
+
+	entries, _, err := loadEntries(fs.Args(), stdin)
+	if err != nil {
+		return err
+	}
+
+	entries, err = filterEntries(entries, *expr)
+	if err != nil {
+		return err
+	}
+
+	c, err := harpostman.NewCollection(
+		&har.HAR{Log: &har.Log{Entries: entries}},
+		harpostman.WithName(*name), harpostman.WithGrouping(grouping), harpostman.WithExamples(*examples))
+	if err != nil {
+		return err
+	}
+
+	w := stdout
+	if *outFile != "" {
+		f, err := os.Create(*outFile)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(c)
+}
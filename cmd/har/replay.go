@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/har"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/harreplay"
+	"io"
+)
+
+func runReplay(fs *flag.FlagSet, args []string, stdin io.Reader, stdout io.Writer) error {
+	baseURL := fs.String("base", "", "base url of the target service (required)")
+	expr := fs.String("e", "", "replay only the entries matching the filter expression (see 'har filter -h')")
+	timing := fs.Bool("timing", false, "preserve the original inter-request timing")
+	speed := fs.Float64("speed", 1, "speed factor applied to the original timing")
+	stopOnError := fs.Bool("stop-on-error", false, "stop at the first transport error")
+	outFile := fs.String("o", "", "output file of the recorded exchanges (default stdout)")
+	indent := fs.Bool("indent", false, "indent the output")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *baseURL == "" {
+		return errors.New("the -base flag is required")
+	}
+
+	entries, inputs, err := loadEntries(fs.Args(), stdin)
+	if err != nil {
+		return err
+	}
+
+	entries, err = filterEntries(entries, *expr)
+	if err != nil {
+		return err
+	}
+
+	r, err := harreplay.NewReplayer(*baseURL, harreplay.WithPreserveTiming(*timing, *speed), harreplay.WithStopOnError(*stopOnError))
+	if err != nil {
+		return err
+	}
+
+	var out *har.HAR
+	out, err = r.Replay(context.Background(), newOutputHAR(inputs, entries))
+	if out != nil {
+		if werr := writeOutput(out, *outFile, *indent, stdout); err == nil {
+			err = werr
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/har"
+	"io"
+	"strings"
+)
+
+func runShow(fs *flag.FlagSet, args []string, stdin io.Reader, stdout io.Writer) error {
+	ndx := fs.Int("i", 0, "index of the entry as reported by 'har list'")
+	asJson := fs.Bool("json", false, "print the entry as json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	entries, _, err := loadEntries(fs.Args(), stdin)
+	if err != nil {
+		return err
+	}
+
+	if *ndx < 0 || *ndx >= len(entries) {
+		return fmt.Errorf("entry index %d out of range [0, %d)", *ndx, len(entries))
+	}
+
+	e := entries[*ndx]
+	if *asJson {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(e)
+	}
+
+	printEntry(stdout, e)
+	return nil
+}
+
+func printEntry(w io.Writer, e *har.Entry) {
+	fmt.Fprintf(w, "started:  %s\n", e.StartedDateTime)
+	fmt.Fprintf(w, "duration: %.0fms\n", e.Time)
+	if e.TraceId != "" {
+		fmt.Fprintf(w, "trace-id: %s\n", e.TraceId)
+	}
+	if e.Comment != "" {
+		fmt.Fprintf(w, "comment:  %s\n", e.Comment)
+	}
+
+	if req := e.Request; req != nil {
+		fmt.Fprintf(w, "\n> %s %s HTTP/%s\n", req.Method, req.URL, strings.TrimPrefix(req.HTTPVersion, "HTTP/"))
+		printHeaders(w, "> ", req.Headers)
+		if req.PostData != nil {
+			printBody(w, req.PostData.MimeType, req.PostData.Body())
+		}
+	}
+
+	if resp := e.Response; resp != nil {
+		fmt.Fprintf(w, "\n< %d %s\n", resp.Status, resp.StatusText)
+		printHeaders(w, "< ", resp.Headers)
+		if resp.Content != nil {
+			printBody(w, resp.Content.MimeType, resp.Content.Body())
+		}
+	}
+}
+
+func printHeaders(w io.Writer, prefix string, nvs har.NameValuePairs) {
+	for _, nv := range nvs {
+		fmt.Fprintf(w, "%s%s: %s\n", prefix, nv.Name, nv.Value)
+	}
+}
+
+func printBody(w io.Writer, mimeType string, body []byte) {
+	if len(body) == 0 {
+		return
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, prettyBody(mimeType, body))
+}
+
+// prettyBody indents json bodies, other contents are returned as they are.
+func prettyBody(mimeType string, body []byte) string {
+	trimmed := bytes.TrimSpace(body)
+	if strings.Contains(mimeType, "json") || (len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[')) {
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, trimmed, "", "  "); err == nil {
+			return buf.String()
+		}
+	}
+
+	return string(body)
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/har"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func runSplit(fs *flag.FlagSet, args []string, stdin io.Reader, stdout io.Writer) error {
+	outFolder := fs.String("o", ".", "output folder")
+	bySpan := fs.Bool("span", false, "split by span id instead of by log id (the id shared by the spans of a trace)")
+	indent := fs.Bool("indent", false, "indent the output")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	entries, inputs, err := loadEntries(fs.Args(), stdin)
+	if err != nil {
+		return err
+	}
+
+	var keys []string
+	groups := make(map[string][]*har.Entry)
+	for _, e := range entries {
+		k := e.TraceId
+		if !*bySpan {
+			k = logIdOf(k)
+		}
+
+		if _, ok := groups[k]; !ok {
+			keys = append(keys, k)
+		}
+		groups[k] = append(groups[k], e)
+	}
+
+	if err := os.MkdirAll(*outFolder, os.ModePerm); err != nil {
+		return err
+	}
+
+	for _, k := range keys {
+		h := newOutputHAR(inputs, groups[k])
+		h.Log.TraceId = k
+
+		fn := filepath.Join(*outFolder, splitFileName(k))
+		if err := h.WriteFile(fn, *indent); err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "%s: %d entries\n", fn, len(groups[k]))
+	}
+
+	return nil
+}
+
+func splitFileName(traceId string) string {
+	if traceId == "" {
+		return "trace-undef.har"
+	}
+	return fmt.Sprintf("trace-%s.har", strings.ReplaceAll(traceId, ":", "_"))
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/har"
+	"io"
+	"math"
+	"net/url"
+	"sort"
+	"text/tabwriter"
+)
+
+type stats struct {
+	Entries      int
+	Traces       int
+	ByMethod     map[string]int
+	ByStatus     map[string]int
+	ByHost       map[string]int
+	MinTime      float64
+	MaxTime      float64
+	AvgTime      float64
+	P50Time      float64
+	P95Time      float64
+	ReqBodySize  int64
+	RespBodySize int64
+}
+
+func computeStats(entries []*har.Entry) stats {
+	st := stats{
+		Entries:  len(entries),
+		ByMethod: map[string]int{},
+		ByStatus: map[string]int{},
+		ByHost:   map[string]int{},
+	}
+
+	traces := map[string]struct{}{}
+	var times []float64
+	for _, e := range entries {
+		traces[logIdOf(e.TraceId)] = struct{}{}
+		times = append(times, e.Time)
+
+		if e.Request != nil {
+			st.ByMethod[e.Request.Method]++
+			if u, err := url.Parse(e.Request.URL); err == nil {
+				st.ByHost[u.Host]++
+			}
+			if e.Request.BodySize > 0 {
+				st.ReqBodySize += e.Request.BodySize
+			}
+		}
+
+		if e.Response != nil {
+			st.ByStatus[fmt.Sprintf("%dxx", e.Response.Status/100)]++
+			if e.Response.BodySize > 0 {
+				st.RespBodySize += e.Response.BodySize
+			}
+		}
+	}
+
+	st.Traces = len(traces)
+	if len(times) > 0 {
+		sort.Float64s(times)
+		st.MinTime = times[0]
+		st.MaxTime = times[len(times)-1]
+		var sum float64
+		for _, t := range times {
+			sum += t
+		}
+		st.AvgTime = sum / float64(len(times))
+		st.P50Time = percentile(times, 50)
+		st.P95Time = percentile(times, 95)
+	}
+
+	return st
+}
+
+// percentile uses the nearest rank method on the sorted values.
+func percentile(sorted []float64, p float64) float64 {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+func runStats(fs *flag.FlagSet, args []string, stdin io.Reader, stdout io.Writer) error {
+	expr := fs.String("e", "", "filter expression (see 'har filter -h')")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	entries, _, err := loadEntries(fs.Args(), stdin)
+	if err != nil {
+		return err
+	}
+
+	entries, err = filterEntries(entries, *expr)
+	if err != nil {
+		return err
+	}
+
+	st := computeStats(entries)
+	tw := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "entries\t%d\n", st.Entries)
+	fmt.Fprintf(tw, "traces\t%d\n", st.Traces)
+	fmt.Fprintf(tw, "time min/avg/p50/p95/max\t%.0f/%.0f/%.0f/%.0f/%.0f ms\n", st.MinTime, st.AvgTime, st.P50Time, st.P95Time, st.MaxTime)
+	fmt.Fprintf(tw, "request bytes\t%d\n", st.ReqBodySize)
+	fmt.Fprintf(tw, "response bytes\t%d\n", st.RespBodySize)
+	printCounts(tw, "method", st.ByMethod)
+	printCounts(tw, "status", st.ByStatus)
+	printCounts(tw, "host", st.ByHost)
+	return tw.Flush()
+}
+
+func printCounts(w io.Writer, label string, counts map[string]int) {
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		fmt.Fprintf(w, "%s %s\t%d\n", label, k, counts[k])
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/har"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/hartestgen"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+func runTestGen(fs *flag.FlagSet, args []string, stdin io.Reader, stdout io.Writer) error {
+	expr := fs.String("e", "", "filter expression (see 'har filter -h')")
+	pkg := fs.String("package", "main_test", "package of the generated file")
+	testName := fs.String("name", "TestHarRegression", "name of the test function")
+	imports := fs.String("imports", "", "comma separated imports, [alias=]path")
+	handler := fs.String("handler", "", "call expression returning the http.Handler under test, e.g. api.NewHandler()")
+	handlerErr := fs.Bool("handler-error", false, "the handler expression returns an error too")
+	fields := fs.String("fields", "", "comma separated json fields to assert (dot paths, * wildcard; default all)")
+	exclude := fs.String("exclude", "", "comma separated json fields to exclude (dot paths, * wildcard)")
+	excludeValues := fs.String("exclude-values", "", "comma separated regular expressions of the values to exclude")
+	volatile := fs.Bool("volatile", true, "exclude the values looking like timestamps and uuids")
+	headers := fs.String("headers", "", "comma separated response headers to assert")
+	outFile := fs.String("o", "", "output file (default stdout)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *handler == "" {
+		return errors.New("the handler constructor is required (-handler)")
+	}
+
+	entries, inputs, err := loadEntries(fs.Args(), stdin)
+	if err != nil {
+		return err
+	}
+
+	entries, err = filterEntries(entries, *expr)
+	if err != nil {
+		return err
+	}
+
+	opts := []hartestgen.Option{
+		hartestgen.WithPackage(*pkg),
+		hartestgen.WithTestName(*testName),
+		hartestgen.WithHandlerConstructor(*handler, *handlerErr),
+		hartestgen.AssertFields(splitList(*fields)...),
+		hartestgen.ExcludeFields(splitList(*exclude)...),
+		hartestgen.AssertHeaders(splitList(*headers)...),
+	}
+
+	if len(inputs) == 1 && inputs[0].name != "stdin" {
+		opts = append(opts, hartestgen.WithSource(inputs[0].name))
+	}
+
+	for _, imp := range splitList(*imports) {
+		alias, path, ok := strings.Cut(imp, "=")
+		if !ok {
+			alias, path = "", imp
+		}
+		opts = append(opts, hartestgen.WithImport(alias, path))
+	}
+
+	if *volatile {
+		opts = append(opts, hartestgen.ExcludeValues(hartestgen.VolatileValuePatterns...))
+	}
+
+	for _, s := range splitList(*excludeValues) {
+		re, err := regexp.Compile(s)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, hartestgen.ExcludeValues(re))
+	}
+
+	w := stdout
+	if *outFile != "" {
+		f, err := os.Create(*outFile)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	return hartestgen.Generate(w, &har.HAR{Log: &har.Log{Entries: entries}}, opts...)
+}
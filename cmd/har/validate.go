@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/har"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/haropenapi"
+	"io"
+)
+
+// runValidate fails when violations are found so that it can be used as a step of a pipeline.
+func runValidate(fs *flag.FlagSet, args []string, stdin io.Reader, stdout io.Writer) error {
+	spec := fs.String("spec", "", "openapi document (yaml or json)")
+	expr := fs.String("e", "", "filter expression (see 'har filter -h')")
+	basePath := fs.String("base-path", "", "prefix removed from the request paths (default the paths of the document servers)")
+	bodies := fs.Bool("bodies", true, "validate the json bodies against the schemas")
+	asJson := fs.Bool("json", false, "print the report as json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *spec == "" {
+		return errors.New("the openapi document is required (-spec)")
+	}
+
+	doc, err := haropenapi.LoadFile(*spec)
+	if err != nil {
+		return err
+	}
+
+	entries, _, err := loadEntries(fs.Args(), stdin)
+	if err != nil {
+		return err
+	}
+
+	entries, err = filterEntries(entries, *expr)
+	if err != nil {
+		return err
+	}
+
+	opts := []haropenapi.ValidatorOption{haropenapi.ValidateRequestBodies(*bodies), haropenapi.ValidateResponseBodies(*bodies)}
+	if *basePath != "" {
+		opts = append(opts, haropenapi.WithBasePath(*basePath))
+	}
+
+	report := haropenapi.Validate(doc, &har.Log{Entries: entries}, opts...)
+	if *asJson {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(report)
+	} else {
+		err = report.Write(stdout)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if !report.Valid() {
+		return fmt.Errorf("%d violations found", len(report.Violations))
+	}
+
+	return nil
+}
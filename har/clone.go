@@ -0,0 +1,64 @@
+package har
+
+import (
+	"maps"
+	"slices"
+)
+
+// Clone returns a deep copy of the entry, the bytes of the bodies aside: they are shared with the original.
+func (e *Entry) Clone() *Entry {
+	if e == nil {
+		return nil
+	}
+
+	c := *e
+	c.Extensions = maps.Clone(e.Extensions)
+
+	if e.Request != nil {
+		req := *e.Request
+		req.PathParams = slices.Clone(e.Request.PathParams)
+		req.Cookies = slices.Clone(e.Request.Cookies)
+		req.Headers = slices.Clone(e.Request.Headers)
+		req.QueryString = slices.Clone(e.Request.QueryString)
+		req.Extensions = maps.Clone(e.Request.Extensions)
+		if e.Request.PostData != nil {
+			po := *e.Request.PostData
+			po.Params = slices.Clone(e.Request.PostData.Params)
+			req.PostData = &po
+		}
+		c.Request = &req
+	}
+
+	if e.Response != nil {
+		resp := *e.Response
+		resp.Cookies = slices.Clone(e.Response.Cookies)
+		resp.Headers = slices.Clone(e.Response.Headers)
+		resp.Extensions = maps.Clone(e.Response.Extensions)
+		if e.Response.Content != nil {
+			content := *e.Response.Content
+			content.Extensions = maps.Clone(e.Response.Content.Extensions)
+			resp.Content = &content
+		}
+		c.Response = &resp
+	}
+
+	if e.Cache != nil {
+		cache := *e.Cache
+		if e.Cache.BeforeRequest != nil {
+			cd := *e.Cache.BeforeRequest
+			cache.BeforeRequest = &cd
+		}
+		if e.Cache.AfterRequest != nil {
+			cd := *e.Cache.AfterRequest
+			cache.AfterRequest = &cd
+		}
+		c.Cache = &cache
+	}
+
+	if e.Timings != nil {
+		t := *e.Timings
+		c.Timings = &t
+	}
+
+	return &c
+}
@@ -0,0 +1,116 @@
+package har
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"github.com/andybalholm/brotli"
+	"io"
+	"strings"
+)
+
+// DefaultMaxDecodedContentSize is the size over which a decoded body is discarded, the content being left encoded.
+const DefaultMaxDecodedContentSize = 32 << 20
+
+// ErrDecodedContentTooLarge is returned when the decoded body exceeds the maximum size.
+var ErrDecodedContentTooLarge = errors.New("har: decoded content exceeds the maximum size")
+
+// WithMaxDecodedContentSize sets the maximum size of the decoded bodies, DefaultMaxDecodedContentSize if <= 0.
+func WithMaxDecodedContentSize(n int64) ConversionOption {
+	return func(o *conversionOpts) {
+		o.maxDecodedSize = n
+	}
+}
+
+// WithContentDecoding decodes the gzip, deflate and br encoded response bodies: the content holds the decoded data and its size,
+// the body size stays the size on the wire and the compression is the difference.
+func WithContentDecoding(b bool) ConversionOption {
+	return func(o *conversionOpts) {
+		o.decodeContent = b
+	}
+}
+
+// DecodeContent reverts the encodings listed in the Content-Encoding header value, applied in order. The decoded body
+// can't exceed maxSize bytes (DefaultMaxDecodedContentSize if <= 0).
+func DecodeContent(contentEncoding string, b []byte, maxSize int64) ([]byte, error) {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxDecodedContentSize
+	}
+
+	encodings := strings.Split(contentEncoding, ",")
+	for i := len(encodings) - 1; i >= 0; i-- {
+		var err error
+		switch enc := strings.ToLower(strings.TrimSpace(encodings[i])); enc {
+		case "", "identity":
+		case "gzip", "x-gzip":
+			var r *gzip.Reader
+			if r, err = gzip.NewReader(bytes.NewReader(b)); err == nil {
+				b, err = readAllLimited(r, maxSize)
+			}
+		case "deflate":
+			b, err = inflate(b, maxSize)
+		case "br":
+			b, err = readAllLimited(brotli.NewReader(bytes.NewReader(b)), maxSize)
+		default:
+			err = fmt.Errorf("unsupported content encoding %s", enc)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return b, nil
+}
+
+// inflate decodes deflate data: zlib wrapped, as mandated, or raw as sent by some servers.
+func inflate(b []byte, maxSize int64) ([]byte, error) {
+	r, err := zlib.NewReader(bytes.NewReader(b))
+	if err == nil {
+		var decoded []byte
+		if decoded, err = readAllLimited(r, maxSize); err == nil || errors.Is(err, ErrDecodedContentTooLarge) {
+			return decoded, err
+		}
+	}
+
+	return readAllLimited(flate.NewReader(bytes.NewReader(b)), maxSize)
+}
+
+func readAllLimited(r io.Reader, maxSize int64) ([]byte, error) {
+	b, err := io.ReadAll(io.LimitReader(r, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(len(b)) > maxSize {
+		return nil, ErrDecodedContentTooLarge
+	}
+
+	return b, nil
+}
+
+// decodeContent replaces the data of the content with the decoded one, setting size and compression.
+func (resp *Response) decodeContent(contentEncoding string, maxSize int64) error {
+	if resp.Content == nil || len(resp.Content.Data) == 0 {
+		return nil
+	}
+
+	decoded, err := DecodeContent(contentEncoding, resp.Content.Data, maxSize)
+	if err != nil {
+		return err
+	}
+
+	resp.Content.Data = decoded
+	resp.Content.Size = int64(len(decoded))
+	resp.Content.Compression = resp.Content.Size - resp.BodySize
+	return nil
+}
+
+// HasDecodedContent tells if the content holds the body decoded from the Content-Encoding of the response, as done by
+// browsers and WithContentDecoding: the body has to be served without the Content-Encoding and Content-Length headers.
+func (resp *Response) HasDecodedContent() bool {
+	return resp.Content != nil && resp.Content.Compression != 0 && resp.Headers.GetFirst("Content-Encoding").Value != ""
+}
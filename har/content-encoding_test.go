@@ -0,0 +1,98 @@
+package har_test
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/har"
+	"github.com/andybalholm/brotli"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestContentDecoding(t *testing.T) {
+	plain := []byte(strings.Repeat(`{"canale":"APPP","lang":"it"}`, 20))
+
+	encode := func(newWriter func(w io.Writer) io.WriteCloser) []byte {
+		var buf bytes.Buffer
+		w := newWriter(&buf)
+		_, err := w.Write(plain)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		return buf.Bytes()
+	}
+
+	gzipped := encode(func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) })
+	tests := []struct {
+		name     string
+		encoding string
+		body     []byte
+	}{
+		{name: "gzip", encoding: "gzip", body: gzipped},
+		{name: "deflate", encoding: "deflate", body: encode(func(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) })},
+		{name: "raw-deflate", encoding: "deflate", body: encode(func(w io.Writer) io.WriteCloser {
+			fw, _ := flate.NewWriter(w, flate.DefaultCompression)
+			return fw
+		})},
+		{name: "brotli", encoding: "br", body: encode(func(w io.Writer) io.WriteCloser { return brotli.NewWriter(w) })},
+		{name: "gzip-then-brotli", encoding: "gzip, br", body: func() []byte {
+			var buf bytes.Buffer
+			w := brotli.NewWriter(&buf)
+			_, _ = w.Write(gzipped)
+			_ = w.Close()
+			return buf.Bytes()
+		}()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(bytes.NewReader(tt.body))}
+			resp.Header.Set("Content-Type", "application/json")
+			resp.Header.Set("Content-Encoding", tt.encoding)
+
+			harResp, err := har.NewResponseFromHttpResponse(resp, har.WithContentDecoding(true))
+			require.NoError(t, err)
+			require.Equal(t, plain, harResp.Content.Data)
+			require.Equal(t, int64(len(plain)), harResp.Content.Size)
+			require.Equal(t, int64(len(tt.body)), harResp.BodySize)
+			require.Equal(t, harResp.Content.Size-harResp.BodySize, harResp.Content.Compression)
+			require.Positive(t, harResp.Content.Compression)
+			require.True(t, harResp.HasDecodedContent())
+
+			b, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.Equal(t, tt.body, b, "the http response keeps the encoded body")
+		})
+	}
+
+	resp := &http.Response{StatusCode: http.StatusOK, Header: http.Header{"Content-Encoding": []string{"gzip"}}, Body: io.NopCloser(bytes.NewReader(gzipped))}
+	harResp, err := har.NewResponseFromHttpResponse(resp)
+	require.NoError(t, err)
+	require.Equal(t, gzipped, harResp.Content.Data, "decoding is optional")
+	require.Zero(t, harResp.Content.Compression)
+
+	resp = &http.Response{StatusCode: http.StatusOK, Header: http.Header{"Content-Encoding": []string{"zstd"}}, Body: io.NopCloser(bytes.NewReader(gzipped))}
+	harResp, err = har.NewResponseFromHttpResponse(resp, har.WithContentDecoding(true))
+	require.NoError(t, err)
+	require.Equal(t, gzipped, harResp.Content.Data, "unsupported encodings are left as they are")
+
+	resp = &http.Response{StatusCode: http.StatusOK, Header: http.Header{"Content-Encoding": []string{"gzip"}}, Body: io.NopCloser(bytes.NewReader(gzipped))}
+	harResp, err = har.NewResponseFromHttpResponse(resp, har.WithContentDecoding(true), har.WithMaxDecodedContentSize(int64(len(plain)-1)))
+	require.NoError(t, err)
+	require.Equal(t, gzipped, harResp.Content.Data, "bodies decoding over the limit are left as they are")
+	require.False(t, harResp.HasDecodedContent())
+
+	_, err = har.DecodeContent("gzip", []byte("not gzipped"), 0)
+	require.Error(t, err)
+
+	_, err = har.DecodeContent("gzip", gzipped, 10)
+	require.ErrorIs(t, err, har.ErrDecodedContentTooLarge)
+
+	b, err := har.DecodeContent("gzip", gzipped, int64(len(plain)))
+	require.NoError(t, err)
+	require.Equal(t, plain, b)
+}
@@ -0,0 +1,93 @@
+package har
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// WithCookieJar adds to the cookies of the request the ones the jar holds for its url and not sent in the Cookie header,
+// e.g. when the request is captured before the client adds them.
+func WithCookieJar(jar http.CookieJar) ConversionOption {
+	return func(o *conversionOpts) {
+		o.jar = jar
+	}
+}
+
+// NewCookie converts an http cookie. The expiration time is the Expires attribute or, if the Max-Age attribute is set, the
+// reference time plus the max age; it's formatted in ISO 8601.
+func NewCookie(c *http.Cookie, ref time.Time) Cookie {
+	hc := Cookie{
+		Name:     c.Name,
+		Value:    c.Value,
+		Path:     c.Path,
+		Domain:   c.Domain,
+		HTTPOnly: c.HttpOnly,
+		Secure:   c.Secure,
+	}
+
+	switch {
+	case c.MaxAge > 0:
+		hc.Expires = ref.Add(time.Duration(c.MaxAge) * time.Second).Format(time.RFC3339Nano)
+	case c.MaxAge < 0:
+		hc.Expires = time.Unix(0, 0).UTC().Format(time.RFC3339Nano)
+	case !c.Expires.IsZero():
+		hc.Expires = c.Expires.Format(time.RFC3339Nano)
+	}
+
+	return hc
+}
+
+// RequestCookies parses the Cookie headers.
+func RequestCookies(h http.Header) []Cookie {
+	cookies := []Cookie{}
+	for _, c := range (&http.Request{Header: h}).Cookies() {
+		cookies = append(cookies, NewCookie(c, time.Time{}))
+	}
+	return cookies
+}
+
+// ResponseCookies parses the Set-Cookie headers. The Date header, if present, is the reference time of the Max-Age attributes.
+func ResponseCookies(h http.Header) []Cookie {
+	ref := time.Now()
+	if d := h.Get("Date"); d != "" {
+		if t, err := http.ParseTime(d); err == nil {
+			ref = t
+		}
+	}
+
+	cookies := []Cookie{}
+	for _, c := range (&http.Response{Header: h}).Cookies() {
+		cookies = append(cookies, NewCookie(c, ref))
+	}
+	return cookies
+}
+
+// addJarCookies appends the cookies of the jar for the url not already present.
+func addJarCookies(cookies []Cookie, jar http.CookieJar, u *url.URL) []Cookie {
+	if jar == nil || u == nil {
+		return cookies
+	}
+
+	sent := make(map[string]struct{}, len(cookies))
+	for _, c := range cookies {
+		sent[c.Name] = struct{}{}
+	}
+
+	for _, c := range jar.Cookies(u) {
+		if _, ok := sent[c.Name]; !ok {
+			cookies = append(cookies, NewCookie(c, time.Time{}))
+		}
+	}
+
+	return cookies
+}
+
+// httpHeader converts the pairs to an http.Header.
+func (nvs NameValuePairs) httpHeader() http.Header {
+	h := make(http.Header, len(nvs))
+	for _, nv := range nvs {
+		h.Add(nv.Name, nv.Value)
+	}
+	return h
+}
@@ -0,0 +1,49 @@
+package har_test
+
+import (
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/har"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestCookies(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/items", nil)
+	req.Header.Add("Cookie", "session=abc; theme=dark")
+
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+	u, _ := url.Parse("http://example.com/")
+	jar.SetCookies(u, []*http.Cookie{{Name: "session", Value: "stale"}, {Name: "lang", Value: "it"}})
+
+	harReq, err := har.NewRequestFromHttpRequest(req, har.WithCookieJar(jar))
+	require.NoError(t, err)
+	require.Equal(t, []har.Cookie{{Name: "session", Value: "abc"}, {Name: "theme", Value: "dark"}, {Name: "lang", Value: "it"}}, harReq.Cookies)
+
+	harReq, err = har.NewRequest(http.MethodGet, "http://example.com/", nil, req.Header, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, harReq.Cookies, 2)
+
+	resp := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}}
+	resp.Header.Set("Date", "Sun, 12 Feb 2023 19:07:02 GMT")
+	resp.Header.Add("Set-Cookie", "session=xyz; Path=/; Domain=example.com; Max-Age=3600; HttpOnly; Secure")
+	resp.Header.Add("Set-Cookie", "theme=light; Expires=Mon, 13 Feb 2023 10:00:00 GMT")
+	harResp, err := har.NewResponseFromHttpResponse(resp)
+	require.NoError(t, err)
+	require.Equal(t, []har.Cookie{
+		{Name: "session", Value: "xyz", Path: "/", Domain: "example.com", Expires: "2023-02-12T20:07:02Z", HTTPOnly: true, Secure: true},
+		{Name: "theme", Value: "light", Expires: "2023-02-13T10:00:00Z"},
+	}, harResp.Cookies)
+
+	harResp = har.NewResponse(http.StatusOK, "OK", "text/plain", nil, har.NameValuePairs{{Name: "Set-Cookie", Value: "a=1"}})
+	require.Equal(t, []har.Cookie{{Name: "a", Value: "1"}}, harResp.Cookies)
+
+	h := har.NewHAR()
+	h.Log.Entries = []*har.Entry{{StartedDateTime: "2023-02-12T20:07:02Z", Request: harReq, Response: harResp, Timings: &har.Timings{}}}
+	for _, issue := range h.Validate() {
+		require.NotContains(t, issue.Path, "cookies", issue.String())
+	}
+}
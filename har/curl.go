@@ -0,0 +1,170 @@
+package har
+
+import (
+	"encoding/base64"
+	"mime"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+// curlSkippedHeaders are computed by curl itself.
+var curlSkippedHeaders = map[string]struct{}{
+	"content-length":    {},
+	"transfer-encoding": {},
+	"connection":        {},
+	"accept-encoding":   {},
+}
+
+type curlOpts struct {
+	multiline bool
+	insecure  bool
+}
+
+type CurlOption func(o *curlOpts)
+
+// CurlMultiline splits the command on several lines, one option per line.
+func CurlMultiline(b bool) CurlOption {
+	return func(o *curlOpts) {
+		o.multiline = b
+	}
+}
+
+// CurlInsecure adds the -k flag to skip the verification of the server certificate.
+func CurlInsecure(b bool) CurlOption {
+	return func(o *curlOpts) {
+		o.insecure = b
+	}
+}
+
+// Curl returns the curl command line reproducing the request of the entry. Arguments are single quoted for POSIX shells.
+// Binary bodies are piped to curl through base64 -d and sent with --data-binary @-; multipart file parts refer to local files
+// named as the posted ones.
+func (e *Entry) Curl(opts ...CurlOption) string {
+	if e.Request == nil {
+		return ""
+	}
+	return e.Request.Curl(opts...)
+}
+
+func (req *Request) Curl(opts ...CurlOption) string {
+	o := curlOpts{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	args := []string{"curl"}
+	if o.insecure {
+		args = append(args, "-k")
+	}
+
+	method := strings.ToUpper(req.Method)
+	if method == "" {
+		method = "GET"
+	}
+
+	// curl switches to POST when data is sent: a GET with a body needs the explicit method.
+	hasData := req.PostData != nil && (len(req.PostData.Body()) > 0 || len(curlParams(req.PostData)) > 0)
+	if method == "HEAD" {
+		args = append(args, "-I")
+	} else if method != "GET" || hasData {
+		args = append(args, "-X "+method)
+	}
+
+	hasCookieHeader := false
+	for _, h := range req.Headers {
+		n := strings.ToLower(h.Name)
+		if _, ok := curlSkippedHeaders[n]; ok || strings.HasPrefix(n, ":") {
+			continue
+		}
+		if n == "cookie" {
+			hasCookieHeader = true
+		}
+		args = append(args, "-H "+shellQuote(h.Name+": "+h.Value))
+	}
+
+	if !hasCookieHeader && len(req.Cookies) > 0 {
+		var cookies []string
+		for _, c := range req.Cookies {
+			cookies = append(cookies, c.Name+"="+c.Value)
+		}
+		args = append(args, "-b "+shellQuote(strings.Join(cookies, "; ")))
+	}
+
+	var stdin string
+	if req.PostData != nil {
+		body := req.PostData.Body()
+		switch {
+		case len(body) > 0 && isBinary(body):
+			stdin = "printf '%s' " + shellQuote(base64.StdEncoding.EncodeToString(body)) + " | base64 -d | "
+			args = append(args, "--data-binary @-")
+		case len(body) > 0:
+			args = append(args, "--data-raw "+shellQuote(string(body)))
+		default:
+			args = append(args, curlParams(req.PostData)...)
+		}
+	}
+
+	args = append(args, shellQuote(curlURL(req)))
+
+	sep := " "
+	if o.multiline {
+		sep = " \\\n  "
+	}
+	return stdin + strings.Join(args, sep)
+}
+
+func curlURL(req *Request) string {
+	u, err := url.Parse(req.URL)
+	if err != nil || u.RawQuery != "" || len(req.QueryString) == 0 {
+		return req.URL
+	}
+
+	q := url.Values{}
+	for _, nv := range req.QueryString {
+		q.Add(nv.Name, nv.Value)
+	}
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+func curlParams(po *PostData) []string {
+	mt, _, _ := mime.ParseMediaType(po.MimeType)
+
+	var args []string
+	for _, p := range po.Params {
+		switch mt {
+		case "multipart/form-data":
+			if p.FileName != "" {
+				v := "@" + p.FileName
+				if p.ContentType != "" {
+					v += ";type=" + p.ContentType
+				}
+				args = append(args, "-F "+shellQuote(p.Name+"="+v))
+			} else {
+				args = append(args, "--form-string "+shellQuote(p.Name+"="+p.Value))
+			}
+		case "application/x-www-form-urlencoded":
+			args = append(args, "--data-urlencode "+shellQuote(p.Name+"="+p.Value))
+		}
+	}
+	return args
+}
+
+func isBinary(b []byte) bool {
+	if !utf8.Valid(b) {
+		return true
+	}
+
+	for _, c := range b {
+		if c < 0x20 && c != '\n' && c != '\r' && c != '\t' {
+			return true
+		}
+	}
+	return false
+}
+
+// shellQuote wraps the string in single quotes, embedded quotes are closed, escaped and reopened.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
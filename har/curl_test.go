@@ -0,0 +1,41 @@
+package har_test
+
+import (
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/har"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestCurl(t *testing.T) {
+	e := har.Entry{Request: &har.Request{
+		Method: "POST",
+		URL:    "http://localhost:3004/api/v1/orc-01",
+		Headers: har.NameValuePairs{
+			{Name: "Content-Type", Value: "application/json"},
+			{Name: "Content-Length", Value: "26"},
+		},
+		QueryString: har.NameValuePairs{{Name: "lang", Value: "it"}},
+		Cookies:     []har.Cookie{{Name: "session", Value: "s1"}},
+		PostData:    &har.PostData{MimeType: "application/json", Text: `{"name":"l'aquila"}`},
+	}}
+
+	require.Equal(t, `curl -X POST -H 'Content-Type: application/json' -b 'session=s1' --data-raw '{"name":"l'\''aquila"}' 'http://localhost:3004/api/v1/orc-01?lang=it'`, e.Curl())
+
+	bin := har.Request{Method: "PUT", URL: "http://localhost/upload", PostData: &har.PostData{MimeType: "application/octet-stream", Data: []byte{0x00, 0xff, 0x10}}}
+	require.Equal(t, `printf '%s' 'AP8Q' | base64 -d | curl -X PUT --data-binary @- 'http://localhost/upload'`, bin.Curl())
+
+	form := har.Request{Method: "POST", URL: "http://localhost/form", PostData: &har.PostData{
+		MimeType: "multipart/form-data; boundary=xyz",
+		Params:   []har.Param{{Name: "descr", Value: "a doc"}, {Name: "doc", FileName: "doc.pdf", ContentType: "application/pdf"}},
+	}}
+	require.Equal(t, "curl \\\n  -X POST \\\n  --form-string 'descr=a doc' \\\n  -F 'doc=@doc.pdf;type=application/pdf' \\\n  'http://localhost/form'", form.Curl(har.CurlMultiline(true)))
+
+	get := har.Request{Method: "GET", URL: "http://localhost/search", PostData: &har.PostData{MimeType: "application/json", Text: `{"q":"a"}`}}
+	require.Equal(t, `curl -X GET --data-raw '{"q":"a"}' 'http://localhost/search'`, get.Curl())
+
+	get = har.Request{Method: "GET", URL: "http://localhost/search", PostData: &har.PostData{MimeType: "application/x-www-form-urlencoded", Params: []har.Param{{Name: "q", Value: "a"}}}}
+	require.Equal(t, `curl -X GET --data-urlencode 'q=a' 'http://localhost/search'`, get.Curl())
+
+	get = har.Request{Method: "GET", URL: "http://localhost/search", PostData: &har.PostData{MimeType: "application/octet-stream"}}
+	require.Equal(t, `curl 'http://localhost/search'`, get.Curl())
+}
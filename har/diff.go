@@ -0,0 +1,460 @@
+package har
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Alignment tells how the entries of two logs are paired before being compared.
+type Alignment int
+
+const (
+	// AlignByOrder pairs the entries by position.
+	AlignByOrder Alignment = iota
+	// AlignByTemplate pairs, in order of appearance, the entries sharing method and url template (see Request.URLTemplate).
+	AlignByTemplate
+	// AlignByTraceId pairs, in order of appearance, the entries sharing trace id, method and url template.
+	AlignByTraceId
+)
+
+func ParseAlignment(s string) (Alignment, error) {
+	switch strings.ToLower(s) {
+	case "", "order":
+		return AlignByOrder, nil
+	case "template":
+		return AlignByTemplate, nil
+	case "trace", "trace-id":
+		return AlignByTraceId, nil
+	}
+
+	return AlignByOrder, fmt.Errorf("unknown alignment %s: wanted one of order, template, trace", s)
+}
+
+type ChangeKind string
+
+const (
+	ChangeStatus ChangeKind = "status"
+	ChangeHeader ChangeKind = "header"
+	ChangeBody   ChangeKind = "body"
+)
+
+// Change is a difference found in a pair of aligned entries. Path identifies the element, e.g. response.headers.content-type
+// or response.body.$.items[0].name. Missing values are reported as the empty string with the Missing flags set.
+type Change struct {
+	Kind       ChangeKind `json:"kind"`
+	Path       string     `json:"path"`
+	Old        string     `json:"old"`
+	New        string     `json:"new"`
+	MissingOld bool       `json:"missingOld,omitempty"`
+	MissingNew bool       `json:"missingNew,omitempty"`
+}
+
+type EntryDiff struct {
+	Key        string   `json:"key"`
+	LeftIndex  int      `json:"leftIndex"`
+	RightIndex int      `json:"rightIndex"`
+	Left       *Entry   `json:"-"`
+	Right      *Entry   `json:"-"`
+	Changes    []Change `json:"changes,omitempty"`
+}
+
+type Diff struct {
+	Added     []*EntryDiff `json:"added,omitempty"`
+	Removed   []*EntryDiff `json:"removed,omitempty"`
+	Changed   []*EntryDiff `json:"changed,omitempty"`
+	Unchanged int          `json:"unchanged"`
+}
+
+func (d *Diff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+type diffOpts struct {
+	alignment       Alignment
+	ignoreHeaders   map[string]struct{}
+	ignoreJsonPaths [][]string
+	compareRequests bool
+}
+
+type DiffOption func(o *diffOpts)
+
+func WithAlignment(a Alignment) DiffOption {
+	return func(o *diffOpts) {
+		o.alignment = a
+	}
+}
+
+// IgnoreHeaders excludes the listed headers (case-insensitive) from the comparison.
+func IgnoreHeaders(names ...string) DiffOption {
+	return func(o *diffOpts) {
+		for _, n := range names {
+			o.ignoreHeaders[strings.ToLower(n)] = struct{}{}
+		}
+	}
+}
+
+// IgnoreJsonFields excludes the fields from the comparison of json bodies. Paths are dot separated, "*" matches any key or array
+// index, e.g. "timestamp" or "items.*.updatedAt".
+func IgnoreJsonFields(paths ...string) DiffOption {
+	return func(o *diffOpts) {
+		for _, p := range paths {
+			o.ignoreJsonPaths = append(o.ignoreJsonPaths, strings.Split(p, "."))
+		}
+	}
+}
+
+// CompareRequests includes request headers and bodies in the comparison. By default only responses are compared.
+func CompareRequests(b bool) DiffOption {
+	return func(o *diffOpts) {
+		o.compareRequests = b
+	}
+}
+
+// Diff aligns the entries of the two logs and reports added and removed calls and the changes of the aligned ones.
+func (log *Log) Diff(other *Log, opts ...DiffOption) *Diff {
+	o := diffOpts{ignoreHeaders: map[string]struct{}{}}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	var left, right []*Entry
+	if log != nil {
+		left = log.Entries
+	}
+	if other != nil {
+		right = other.Entries
+	}
+
+	d := &Diff{}
+	for _, p := range o.align(left, right) {
+		switch {
+		case p.Left == nil:
+			d.Added = append(d.Added, p)
+		case p.Right == nil:
+			d.Removed = append(d.Removed, p)
+		default:
+			p.Changes = o.compareEntries(p.Left, p.Right)
+			if len(p.Changes) > 0 {
+				d.Changed = append(d.Changed, p)
+			} else {
+				d.Unchanged++
+			}
+		}
+	}
+
+	return d
+}
+
+func (o *diffOpts) key(e *Entry) string {
+	if e.Request == nil {
+		return ""
+	}
+
+	switch o.alignment {
+	case AlignByTraceId:
+		return e.TraceId + " " + e.Request.URLTemplate()
+	default:
+		return e.Request.URLTemplate()
+	}
+}
+
+func (o *diffOpts) align(left, right []*Entry) []*EntryDiff {
+	var pairs []*EntryDiff
+	if o.alignment == AlignByOrder {
+		for i := 0; i < len(left) || i < len(right); i++ {
+			p := &EntryDiff{LeftIndex: -1, RightIndex: -1}
+			if i < len(left) {
+				p.Left, p.LeftIndex, p.Key = left[i], i, o.key(left[i])
+			}
+			if i < len(right) {
+				p.Right, p.RightIndex = right[i], i
+				if p.Key == "" {
+					p.Key = o.key(right[i])
+				}
+			}
+			pairs = append(pairs, p)
+		}
+		return pairs
+	}
+
+	queues := map[string][]int{}
+	for j, e := range right {
+		k := o.key(e)
+		queues[k] = append(queues[k], j)
+	}
+
+	matched := make([]bool, len(right))
+	for i, e := range left {
+		k := o.key(e)
+		p := &EntryDiff{Key: k, Left: e, LeftIndex: i, RightIndex: -1}
+		if q := queues[k]; len(q) > 0 {
+			p.Right, p.RightIndex = right[q[0]], q[0]
+			matched[q[0]] = true
+			queues[k] = q[1:]
+		}
+		pairs = append(pairs, p)
+	}
+
+	for j, e := range right {
+		if !matched[j] {
+			pairs = append(pairs, &EntryDiff{Key: o.key(e), Right: e, LeftIndex: -1, RightIndex: j})
+		}
+	}
+
+	return pairs
+}
+
+func (o *diffOpts) compareEntries(l, r *Entry) []Change {
+	var changes []Change
+
+	if o.compareRequests && l.Request != nil && r.Request != nil {
+		changes = append(changes, o.compareHeaders("request.headers", l.Request.Headers, r.Request.Headers)...)
+		changes = append(changes, o.compareBodies("request.body", postDataBody(l.Request), postDataBody(r.Request))...)
+	}
+
+	lr, rr := l.Response, r.Response
+	if lr == nil || rr == nil {
+		if lr != rr {
+			changes = append(changes, Change{Kind: ChangeStatus, Path: "response", Old: responseStatus(lr), New: responseStatus(rr), MissingOld: lr == nil, MissingNew: rr == nil})
+		}
+		return changes
+	}
+
+	if lr.Status != rr.Status {
+		changes = append(changes, Change{Kind: ChangeStatus, Path: "response.status", Old: strconv.Itoa(lr.Status), New: strconv.Itoa(rr.Status)})
+	}
+
+	changes = append(changes, o.compareHeaders("response.headers", lr.Headers, rr.Headers)...)
+	changes = append(changes, o.compareBodies("response.body", contentBody(lr), contentBody(rr))...)
+	return changes
+}
+
+func responseStatus(r *Response) string {
+	if r == nil {
+		return ""
+	}
+	return strconv.Itoa(r.Status)
+}
+
+func postDataBody(r *Request) []byte {
+	if r.PostData == nil {
+		return nil
+	}
+	return r.PostData.Body()
+}
+
+func contentBody(r *Response) []byte {
+	if r.Content == nil {
+		return nil
+	}
+	return r.Content.Body()
+}
+
+func (o *diffOpts) compareHeaders(prefix string, l, r NameValuePairs) []Change {
+	lm := o.headerMap(l)
+	rm := o.headerMap(r)
+
+	names := make([]string, 0, len(lm)+len(rm))
+	for n := range lm {
+		names = append(names, n)
+	}
+	for n := range rm {
+		if _, ok := lm[n]; !ok {
+			names = append(names, n)
+		}
+	}
+	sort.Strings(names)
+
+	var changes []Change
+	for _, n := range names {
+		lv, lok := lm[n]
+		rv, rok := rm[n]
+		if lok && rok && lv == rv {
+			continue
+		}
+		changes = append(changes, Change{Kind: ChangeHeader, Path: prefix + "." + n, Old: lv, New: rv, MissingOld: !lok, MissingNew: !rok})
+	}
+	return changes
+}
+
+func (o *diffOpts) headerMap(nvs NameValuePairs) map[string]string {
+	m := map[string]string{}
+	for _, nv := range nvs {
+		n := strings.ToLower(nv.Name)
+		if _, ok := o.ignoreHeaders[n]; ok {
+			continue
+		}
+		if v, ok := m[n]; ok {
+			m[n] = v + ", " + nv.Value
+		} else {
+			m[n] = nv.Value
+		}
+	}
+	return m
+}
+
+func (o *diffOpts) compareBodies(prefix string, l, r []byte) []Change {
+	var lv, rv interface{}
+	lerr := json.Unmarshal(l, &lv)
+	rerr := json.Unmarshal(r, &rv)
+	if len(bytes.TrimSpace(l)) > 0 && len(bytes.TrimSpace(r)) > 0 && lerr == nil && rerr == nil {
+		var changes []Change
+		o.compareJson(prefix+".$", nil, lv, rv, &changes)
+		return changes
+	}
+
+	if bytes.Equal(l, r) {
+		return nil
+	}
+
+	return []Change{{Kind: ChangeBody, Path: prefix, Old: string(l), New: string(r), MissingOld: len(l) == 0, MissingNew: len(r) == 0}}
+}
+
+func (o *diffOpts) ignored(path []string) bool {
+	for _, ip := range o.ignoreJsonPaths {
+		if len(ip) != len(path) {
+			continue
+		}
+
+		match := true
+		for i := range ip {
+			if ip[i] != "*" && ip[i] != path[i] {
+				match = false
+				break
+			}
+		}
+
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+func (o *diffOpts) compareJson(label string, path []string, l, r interface{}, changes *[]Change) {
+	if o.ignored(path) {
+		return
+	}
+
+	switch lt := l.(type) {
+	case map[string]interface{}:
+		if rt, ok := r.(map[string]interface{}); ok {
+			keys := make([]string, 0, len(lt)+len(rt))
+			for k := range lt {
+				keys = append(keys, k)
+			}
+			for k := range rt {
+				if _, ok := lt[k]; !ok {
+					keys = append(keys, k)
+				}
+			}
+			sort.Strings(keys)
+
+			for _, k := range keys {
+				lv, lok := lt[k]
+				rv, rok := rt[k]
+				childPath := append(append([]string{}, path...), k)
+				if o.ignored(childPath) {
+					continue
+				}
+
+				if !lok || !rok {
+					*changes = append(*changes, Change{Kind: ChangeBody, Path: label + "." + k, Old: jsonString(lv, lok), New: jsonString(rv, rok), MissingOld: !lok, MissingNew: !rok})
+					continue
+				}
+				o.compareJson(label+"."+k, childPath, lv, rv, changes)
+			}
+			return
+		}
+
+	case []interface{}:
+		if rt, ok := r.([]interface{}); ok {
+			for i := 0; i < len(lt) || i < len(rt); i++ {
+				childPath := append(append([]string{}, path...), strconv.Itoa(i))
+				childLabel := fmt.Sprintf("%s[%d]", label, i)
+				if o.ignored(childPath) {
+					continue
+				}
+
+				if i >= len(lt) || i >= len(rt) {
+					*changes = append(*changes, Change{Kind: ChangeBody, Path: childLabel, Old: jsonString(at(lt, i)), New: jsonString(at(rt, i)), MissingOld: i >= len(lt), MissingNew: i >= len(rt)})
+					continue
+				}
+				o.compareJson(childLabel, childPath, lt[i], rt[i], changes)
+			}
+			return
+		}
+	}
+
+	ls, _ := json.Marshal(l)
+	rs, _ := json.Marshal(r)
+	if !bytes.Equal(ls, rs) {
+		*changes = append(*changes, Change{Kind: ChangeBody, Path: label, Old: string(ls), New: string(rs)})
+	}
+}
+
+func at(a []interface{}, i int) (interface{}, bool) {
+	if i < len(a) {
+		return a[i], true
+	}
+	return nil, false
+}
+
+func jsonString(v interface{}, ok bool) string {
+	if !ok {
+		return ""
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+// Write prints a human readable report of the differences.
+func (d *Diff) Write(w io.Writer) error {
+	var sb strings.Builder
+	if d.IsEmpty() {
+		sb.WriteString(fmt.Sprintf("no differences (%d entries compared)\n", d.Unchanged))
+		_, err := io.WriteString(w, sb.String())
+		return err
+	}
+
+	for _, p := range d.Removed {
+		sb.WriteString(fmt.Sprintf("- [%d] %s\n", p.LeftIndex, entryLabel(p.Left)))
+	}
+	for _, p := range d.Added {
+		sb.WriteString(fmt.Sprintf("+ [%d] %s\n", p.RightIndex, entryLabel(p.Right)))
+	}
+	for _, p := range d.Changed {
+		sb.WriteString(fmt.Sprintf("~ [%d -> %d] %s\n", p.LeftIndex, p.RightIndex, entryLabel(p.Left)))
+		for _, c := range p.Changes {
+			switch {
+			case c.MissingOld:
+				sb.WriteString(fmt.Sprintf("    %s: added %s\n", c.Path, c.New))
+			case c.MissingNew:
+				sb.WriteString(fmt.Sprintf("    %s: removed %s\n", c.Path, c.Old))
+			default:
+				sb.WriteString(fmt.Sprintf("    %s: %s -> %s\n", c.Path, c.Old, c.New))
+			}
+		}
+	}
+
+	sb.WriteString(fmt.Sprintf("%d removed, %d added, %d changed, %d unchanged\n", len(d.Removed), len(d.Added), len(d.Changed), d.Unchanged))
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func entryLabel(e *Entry) string {
+	if e == nil || e.Request == nil {
+		return "<no request>"
+	}
+
+	s := e.Request.Method + " " + e.Request.URL
+	if e.Response != nil {
+		s += " -> " + strconv.Itoa(e.Response.Status)
+	}
+	return s
+}
@@ -0,0 +1,62 @@
+package har_test
+
+import (
+	"bytes"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/har"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+)
+
+func diffEntry(method, u string, status int, body string, traceId string, headers ...har.NameValuePair) *har.Entry {
+	return &har.Entry{
+		Request:  &har.Request{Method: method, URL: u},
+		Response: har.NewResponse(status, http.StatusText(status), "application/json", []byte(body), headers),
+		TraceId:  traceId,
+	}
+}
+
+func TestDiff(t *testing.T) {
+
+	left := har.NewHAR(
+		har.WithEntry(diffEntry(http.MethodGet, "http://svc/api/items/10", 200, `{"id":10,"name":"a","ts":"2023-01-01","tags":["x"]}`, "t1", har.NameValuePair{Name: "Date", Value: "yesterday"})),
+		har.WithEntry(diffEntry(http.MethodGet, "http://svc/api/items/11", 200, `{"id":11}`, "t2")),
+		har.WithEntry(diffEntry(http.MethodDelete, "http://svc/api/items/12", 204, ``, "t3")),
+	)
+
+	right := har.NewHAR(
+		har.WithEntry(diffEntry(http.MethodGet, "http://svc/api/items/11", 500, `{"id":11}`, "t2")),
+		har.WithEntry(diffEntry(http.MethodGet, "http://svc/api/items/10", 200, `{"id":10,"name":"b","ts":"2024-01-01","tags":["x","y"]}`, "t1", har.NameValuePair{Name: "Date", Value: "today"}, har.NameValuePair{Name: "X-New", Value: "1"})),
+		har.WithEntry(diffEntry(http.MethodPost, "http://svc/api/items", 201, `{"id":13}`, "t4")),
+	)
+
+	d := left.Log.Diff(right.Log, har.WithAlignment(har.AlignByTraceId), har.IgnoreHeaders("date"), har.IgnoreJsonFields("ts"))
+
+	var buf bytes.Buffer
+	require.NoError(t, d.Write(&buf))
+	t.Log("\n" + buf.String())
+
+	require.Len(t, d.Removed, 1)
+	require.Equal(t, http.MethodDelete, d.Removed[0].Left.Request.Method)
+	require.Len(t, d.Added, 1)
+	require.Equal(t, http.MethodPost, d.Added[0].Right.Request.Method)
+	require.Len(t, d.Changed, 2)
+
+	var paths []string
+	for _, p := range d.Changed {
+		for _, c := range p.Changes {
+			paths = append(paths, c.Path)
+		}
+	}
+	require.ElementsMatch(t, []string{"response.headers.x-new", "response.body.$.name", "response.body.$.tags[1]", "response.status"}, paths)
+
+	byOrder := left.Log.Diff(left.Log)
+	require.True(t, byOrder.IsEmpty())
+	require.Equal(t, 3, byOrder.Unchanged)
+}
+
+func TestPathTemplate(t *testing.T) {
+	require.Equal(t, "/api/v1/items/{id}/tags/{id}", har.PathTemplate("/api/v1/items/10724279/tags/3f2504e0-4f89-11d3-9a0c-0305e82c3301"))
+	require.Equal(t, "/api/v1/orc-001", har.PathTemplate("/api/v1/orc-001"))
+	require.Equal(t, "GET /example-01/api/v1/endpoint-01/{id}", (&har.Request{Method: "get", URL: "http://localhost:3004/example-01/api/v1/endpoint-01/10724279"}).URLTemplate())
+}
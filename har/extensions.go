@@ -0,0 +1,261 @@
+package har
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Extensions holds the custom fields (by convention named with a leading underscore) added by browsers and tools
+// and not modelled by the structs. They are preserved when a document is read and written back.
+type Extensions map[string]json.RawMessage
+
+// Get decodes the value of the named field in v, it returns false if the field is not present.
+func (x Extensions) Get(name string, v interface{}) (bool, error) {
+	raw, ok := x[name]
+	if !ok {
+		return false, nil
+	}
+
+	return true, json.Unmarshal(raw, v)
+}
+
+// Set stores the json encoding of v as the value of the named field.
+func (x *Extensions) Set(name string, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	if *x == nil {
+		*x = Extensions{}
+	}
+
+	(*x)[name] = b
+	return nil
+}
+
+func (x Extensions) Delete(name string) {
+	delete(x, name)
+}
+
+// knownFields caches, per struct type, the json names of the modelled fields.
+var knownFields sync.Map
+
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	if names, ok := knownFields.Load(t); ok {
+		return names.(map[string]bool)
+	}
+
+	names := map[string]bool{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		n := f.Name
+		if tag, ok := f.Tag.Lookup("json"); ok {
+			if tag == "-" {
+				continue
+			}
+
+			if tn, _, _ := strings.Cut(tag, ","); tn != "" {
+				n = tn
+			}
+		}
+		names[n] = true
+	}
+
+	knownFields.Store(t, names)
+	return names
+}
+
+// unmarshalWithExtensions decodes the object in v, a pointer to a struct, and returns the underscore fields not modelled by the struct.
+func unmarshalWithExtensions(b []byte, v interface{}) (Extensions, error) {
+	if err := json.Unmarshal(b, v); err != nil {
+		return nil, err
+	}
+
+	if !bytes.Contains(b, []byte(`"_`)) {
+		return nil, nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, err
+	}
+
+	known := jsonFieldNames(reflect.TypeOf(v).Elem())
+
+	var x Extensions
+	for n, raw := range fields {
+		if strings.HasPrefix(n, "_") && !known[n] {
+			if x == nil {
+				x = Extensions{}
+			}
+			x[n] = raw
+		}
+	}
+
+	return x, nil
+}
+
+// marshalWithExtensions encodes v, a pointer to a struct, appending the extensions not clashing with the modelled fields.
+func marshalWithExtensions(v interface{}, x Extensions) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil || len(x) == 0 {
+		return b, err
+	}
+
+	known := jsonFieldNames(reflect.TypeOf(v).Elem())
+	names := make([]string, 0, len(x))
+	for n := range x {
+		if !known[n] {
+			names = append(names, n)
+		}
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	buf.Write(b[:len(b)-1])
+	for i, n := range names {
+		if i > 0 || len(b) > 2 {
+			buf.WriteByte(',')
+		}
+
+		k, _ := json.Marshal(n)
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(x[n])
+	}
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+func (l *Log) UnmarshalJSON(b []byte) error {
+	type log Log
+	x, err := unmarshalWithExtensions(b, (*log)(l))
+	l.Extensions = x
+	return err
+}
+
+func (l *Log) MarshalJSON() ([]byte, error) {
+	type log Log
+	return marshalWithExtensions((*log)(l), l.Extensions)
+}
+
+func (e *Entry) UnmarshalJSON(b []byte) error {
+	type entry Entry
+	x, err := unmarshalWithExtensions(b, (*entry)(e))
+	e.Extensions = x
+	return err
+}
+
+func (e *Entry) MarshalJSON() ([]byte, error) {
+	type entry Entry
+	return marshalWithExtensions((*entry)(e), e.Extensions)
+}
+
+func (req *Request) UnmarshalJSON(b []byte) error {
+	type request Request
+	x, err := unmarshalWithExtensions(b, (*request)(req))
+	req.Extensions = x
+	return err
+}
+
+func (req *Request) MarshalJSON() ([]byte, error) {
+	type request Request
+	return marshalWithExtensions((*request)(req), req.Extensions)
+}
+
+func (resp *Response) UnmarshalJSON(b []byte) error {
+	type response Response
+	x, err := unmarshalWithExtensions(b, (*response)(resp))
+	resp.Extensions = x
+	return err
+}
+
+func (resp *Response) MarshalJSON() ([]byte, error) {
+	type response Response
+	return marshalWithExtensions((*response)(resp), resp.Extensions)
+}
+
+func (c *Content) UnmarshalJSON(b []byte) error {
+	type content Content
+	x, err := unmarshalWithExtensions(b, (*content)(c))
+	c.Extensions = x
+	return err
+}
+
+// Chrome DevTools extensions.
+
+const (
+	ExtInitiator         = "_initiator"
+	ExtResourceType      = "_resourceType"
+	ExtTransferSize      = "_transferSize"
+	ExtWebSocketMessages = "_webSocketMessages"
+)
+
+// Initiator describes what caused the request (Chrome _initiator).
+type Initiator struct {
+	Type       string          `json:"type" yaml:"type" mapstructure:"type"` // parser, script, preload, other...
+	URL        string          `json:"url,omitempty" yaml:"url,omitempty" mapstructure:"url,omitempty"`
+	LineNumber *int            `json:"lineNumber,omitempty" yaml:"lineNumber,omitempty" mapstructure:"lineNumber,omitempty"`
+	Stack      json.RawMessage `json:"stack,omitempty" yaml:"-" mapstructure:"-"` // call stack, kept as is.
+}
+
+// WebSocketMessage is a frame exchanged over a websocket connection (Chrome _webSocketMessages).
+type WebSocketMessage struct {
+	Type   string  `json:"type" yaml:"type" mapstructure:"type"` // send or receive
+	Time   float64 `json:"time" yaml:"time" mapstructure:"time"` // seconds since the epoch
+	Opcode int     `json:"opcode" yaml:"opcode" mapstructure:"opcode"`
+	Data   string  `json:"data" yaml:"data" mapstructure:"data"`
+}
+
+// Initiator returns the _initiator extension, nil if not present.
+func (e *Entry) Initiator() (*Initiator, error) {
+	var ini Initiator
+	ok, err := e.Extensions.Get(ExtInitiator, &ini)
+	if !ok || err != nil {
+		return nil, err
+	}
+	return &ini, nil
+}
+
+func (e *Entry) SetInitiator(ini *Initiator) error {
+	return e.Extensions.Set(ExtInitiator, ini)
+}
+
+// ResourceType returns the _resourceType extension (document, script, xhr, fetch, websocket...), empty if not present.
+func (e *Entry) ResourceType() string {
+	var rt string
+	_, _ = e.Extensions.Get(ExtResourceType, &rt)
+	return rt
+}
+
+func (e *Entry) SetResourceType(rt string) error {
+	return e.Extensions.Set(ExtResourceType, rt)
+}
+
+// WebSocketMessages returns the _webSocketMessages extension.
+func (e *Entry) WebSocketMessages() ([]WebSocketMessage, error) {
+	var msgs []WebSocketMessage
+	_, err := e.Extensions.Get(ExtWebSocketMessages, &msgs)
+	return msgs, err
+}
+
+func (e *Entry) SetWebSocketMessages(msgs []WebSocketMessage) error {
+	return e.Extensions.Set(ExtWebSocketMessages, msgs)
+}
+
+// TransferSize returns the _transferSize extension: the bytes received over the network, headers included.
+func (resp *Response) TransferSize() (int64, bool) {
+	var n int64
+	ok, err := resp.Extensions.Get(ExtTransferSize, &n)
+	return n, ok && err == nil
+}
+
+func (resp *Response) SetTransferSize(n int64) error {
+	return resp.Extensions.Set(ExtTransferSize, n)
+}
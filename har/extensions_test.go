@@ -0,0 +1,88 @@
+package har_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/har"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+const chromeHAR = `{"log":{"version":"1.2","creator":{"name":"WebInspector","version":"537.36"},"_custom":{"a":1},"entries":[{
+	"_initiator":{"type":"script","url":"https://example.com/app.js","lineNumber":12,"stack":{"callFrames":[]}},
+	"_priority":"High",
+	"_resourceType":"websocket",
+	"_webSocketMessages":[{"type":"send","time":1676228822.147,"opcode":1,"data":"hello"},{"type":"receive","time":1676228822.2,"opcode":1,"data":"world"}],
+	"_trace-id":"a-trace","_pii":{},
+	"startedDateTime":"2023-02-12T20:07:02.147874+01:00","time":5,
+	"request":{"method":"GET","url":"wss://example.com/ws","httpVersion":"HTTP/1.1","cookies":[],"headers":[],"queryString":[],"headersSize":-1,"bodySize":0,"_isLinkPreload":false},
+	"response":{"status":101,"statusText":"Switching Protocols","httpVersion":"HTTP/1.1","cookies":[],"headers":[],"redirectURL":"","headersSize":-1,"bodySize":0,
+		"content":{"size":0,"mimeType":"x-unknown","_sha":"abc"},"_transferSize":129,"_error":null},
+	"cache":{},"timings":{"send":1,"wait":2,"receive":2}
+}]}}`
+
+func TestExtensions(t *testing.T) {
+	h, err := har.ReadHAR(strings.NewReader(chromeHAR))
+	require.NoError(t, err)
+
+	require.Contains(t, h.Log.Extensions, "_custom")
+	e := h.Log.Entries[0]
+	require.Equal(t, "a-trace", e.TraceId)
+	require.NotContains(t, e.Extensions, "_trace-id", "modelled fields are not extensions")
+
+	ini, err := e.Initiator()
+	require.NoError(t, err)
+	require.Equal(t, "script", ini.Type)
+	require.Equal(t, 12, *ini.LineNumber)
+	require.Equal(t, "websocket", e.ResourceType())
+
+	msgs, err := e.WebSocketMessages()
+	require.NoError(t, err)
+	require.Len(t, msgs, 2)
+	require.Equal(t, "world", msgs[1].Data)
+
+	n, ok := e.Response.TransferSize()
+	require.True(t, ok)
+	require.Equal(t, int64(129), n)
+	require.Contains(t, e.Request.Extensions, "_isLinkPreload")
+	require.Contains(t, e.Response.Content.Extensions, "_sha")
+
+	var priority string
+	ok, err = e.Extensions.Get("_priority", &priority)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "High", priority)
+
+	var buf bytes.Buffer
+	require.NoError(t, h.Write(&buf, false))
+
+	var expected, actual interface{}
+	require.NoError(t, json.Unmarshal([]byte(chromeHAR), &expected))
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &actual))
+	require.Equal(t, expected, actual, "custom fields survive the round trip")
+
+	e = &har.Entry{}
+	require.Empty(t, e.ResourceType())
+	_, ok = (&har.Response{}).TransferSize()
+	require.False(t, ok)
+	require.NoError(t, e.SetResourceType("fetch"))
+	b, err := json.Marshal(e)
+	require.NoError(t, err)
+	require.Contains(t, string(b), `"_resourceType":"fetch"`)
+}
+
+func TestMergeExtensions(t *testing.T) {
+	var h1, h2 har.HAR
+	require.NoError(t, json.Unmarshal([]byte(`{"log":{"version":"1.2","_custom":{"a":1},"_tool":"x","entries":[]}}`), &h1))
+	require.NoError(t, json.Unmarshal([]byte(`{"log":{"version":"1.2","_custom":{"a":2},"_other":true,"entries":[]}}`), &h2))
+
+	m, err := h1.Merge(&h2, func(e1, e2 *har.Entry) bool { return false })
+	require.NoError(t, err)
+
+	b, err := json.Marshal(m)
+	require.NoError(t, err)
+	require.Contains(t, string(b), `"_custom":{"a":1}`)
+	require.Contains(t, string(b), `"_tool":"x"`)
+	require.Contains(t, string(b), `"_other":true`)
+}
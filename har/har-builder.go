@@ -5,6 +5,7 @@ type BuilderOption func(hrab *Builder)
 type Builder struct {
 	creator        string
 	creatorVersion string
+	version        string
 	comment        string
 	entries        []*Entry
 }
@@ -22,6 +23,13 @@ func WithCreator(creator string, version string) BuilderOption {
 	}
 }
 
+// WithVersion sets the version of the document, DefaultVersion if not set.
+func WithVersion(v string) BuilderOption {
+	return func(hrab *Builder) {
+		hrab.version = v
+	}
+}
+
 func WithComment(comment string) BuilderOption {
 	return func(hrab *Builder) {
 		hrab.comment = comment
@@ -30,14 +38,14 @@ func WithComment(comment string) BuilderOption {
 
 func NewHAR(opts ...BuilderOption) *HAR {
 
-	harb := Builder{creator: "rest-client", creatorVersion: "1.0"}
+	harb := Builder{creator: "rest-client", creatorVersion: "1.0", version: DefaultVersion}
 	for _, o := range opts {
 		o(&harb)
 	}
 
 	har := HAR{
 		Log: &Log{
-			Version: "1.1",
+			Version: harb.version,
 			Creator: &Creator{
 				Name:    harb.creator,
 				Version: harb.creatorVersion,
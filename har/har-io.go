@@ -0,0 +1,61 @@
+package har
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+)
+
+// ReadHAR decodes a har document from the reader. Documents of versions older than DefaultVersion are upgraded.
+func ReadHAR(r io.Reader) (*HAR, error) {
+	var h HAR
+	err := json.NewDecoder(r).Decode(&h)
+	if err != nil {
+		return nil, err
+	}
+
+	if h.Log == nil {
+		h.Log = &Log{}
+	}
+
+	// documents of older versions are read into the current model.
+	if isOlderVersion(h.Log.Version) {
+		h.Log.upgrade()
+	}
+
+	return &h, nil
+}
+
+// LoadFile reads the har document stored in the named file.
+func LoadFile(fn string) (*HAR, error) {
+	f, err := os.Open(fn)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ReadHAR(f)
+}
+
+// Write encodes the har document on the writer, indented if requested.
+func (h *HAR) Write(w io.Writer, indent bool) error {
+	enc := json.NewEncoder(w)
+	if indent {
+		enc.SetIndent("", "  ")
+	}
+	return enc.Encode(h)
+}
+
+// WriteFile stores the har document in the named file.
+func (h *HAR) WriteFile(fn string, indent bool) error {
+	f, err := os.Create(fn)
+	if err != nil {
+		return err
+	}
+
+	err = h.Write(f, indent)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
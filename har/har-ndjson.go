@@ -0,0 +1,169 @@
+package har
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+)
+
+// NDJSON (a.k.a. JSON Lines) format: one entry per line, each carrying its trace id.
+
+// LineEncoder writes entries in NDJSON format.
+type LineEncoder struct {
+	w io.Writer
+}
+
+func NewLineEncoder(w io.Writer) *LineEncoder {
+	return &LineEncoder{w: w}
+}
+
+// Encode writes the entry on a single line.
+func (enc *LineEncoder) Encode(e *Entry) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	_, err = enc.w.Write(append(b, '\n'))
+	return err
+}
+
+// LineDecoder reads entries in NDJSON format. Blank lines are skipped.
+type LineDecoder struct {
+	r    *bufio.Reader
+	line int
+}
+
+func NewLineDecoder(r io.Reader) *LineDecoder {
+	return &LineDecoder{r: bufio.NewReader(r)}
+}
+
+// Next returns the entry of the next line, io.EOF when the lines are over.
+func (d *LineDecoder) Next() (*Entry, error) {
+	for {
+		b, err := d.r.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+
+		if len(b) > 0 {
+			d.line++
+		}
+
+		if b = bytes.TrimSpace(b); len(b) > 0 {
+			var e Entry
+			if uerr := json.Unmarshal(b, &e); uerr != nil {
+				return nil, &LineError{Line: d.line, Err: uerr}
+			}
+			return &e, nil
+		}
+
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+	}
+}
+
+// Entries iterates over the entries, the iteration stops at the first error.
+func (d *LineDecoder) Entries() iter.Seq2[*Entry, error] {
+	return func(yield func(*Entry, error) bool) {
+		for {
+			e, err := d.Next()
+			if err == io.EOF {
+				return
+			}
+
+			if !yield(e, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// LineError reports the line of an entry that cannot be decoded.
+type LineError struct {
+	Line int
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("har: line %d: %v", e.Line, e.Err)
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// ReadNDJSON reads the entries in NDJSON format into a har document.
+func ReadNDJSON(r io.Reader) (*HAR, error) {
+	h := NewHAR()
+	for e, err := range NewLineDecoder(r).Entries() {
+		if err != nil {
+			return nil, err
+		}
+		h.Log.Entries = append(h.Log.Entries, e)
+	}
+
+	return h, nil
+}
+
+// WriteNDJSON writes the entries of the document in NDJSON format. Entries without a trace id get the one of the log.
+func (h *HAR) WriteNDJSON(w io.Writer) error {
+	if h.Log == nil {
+		return nil
+	}
+
+	enc := NewLineEncoder(w)
+	for _, e := range h.Log.Entries {
+		if err := enc.Encode(withLogTraceId(e, h.Log.TraceId)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ConvertHARToNDJSON converts a har document in NDJSON format without loading it in memory.
+// Entries without a trace id get the one of the log, if it precedes the entries in the document.
+func ConvertHARToNDJSON(r io.Reader, w io.Writer) error {
+	dec := NewDecoder(r)
+	header, err := dec.Header()
+	if err != nil {
+		return err
+	}
+
+	enc := NewLineEncoder(w)
+	return dec.ForEach(func(e *Entry) error {
+		return enc.Encode(withLogTraceId(e, header.TraceId))
+	})
+}
+
+// ConvertNDJSONToHAR converts entries in NDJSON format in a har document, with the fields of header, without loading
+// them in memory.
+func ConvertNDJSONToHAR(r io.Reader, w io.Writer, header *Log) error {
+	enc := NewEncoder(w, header)
+	for e, err := range NewLineDecoder(r).Entries() {
+		if err != nil {
+			return err
+		}
+
+		if err = enc.Encode(e); err != nil {
+			return err
+		}
+	}
+
+	return enc.Close()
+}
+
+func withLogTraceId(e *Entry, traceId string) *Entry {
+	if e.TraceId != "" || traceId == "" {
+		return e
+	}
+
+	ec := *e
+	ec.TraceId = traceId
+	return &ec
+}
@@ -0,0 +1,49 @@
+package har_test
+
+import (
+	"bytes"
+	"errors"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/har"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func TestNDJSON(t *testing.T) {
+	h := har.NewHAR(
+		har.WithEntry(&har.Entry{StartedDateTime: "2023-02-12T20:07:02.147874+01:00", Time: 1, TraceId: "log-01:log-01:span-01:1"}),
+		har.WithEntry(&har.Entry{StartedDateTime: "2023-02-12T20:07:02.149311+01:00", Time: 2}),
+	)
+	h.Log.TraceId = "log-02:log-02:span-02:1"
+
+	var buf bytes.Buffer
+	require.NoError(t, h.WriteNDJSON(&buf))
+	t.Log(buf.String())
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	require.Contains(t, lines[1], `"_trace-id":"log-02:log-02:span-02:1"`)
+	require.Empty(t, h.Log.Entries[1].TraceId, "the source entries are not modified")
+
+	h2, err := har.ReadNDJSON(strings.NewReader(buf.String() + "\n\n"))
+	require.NoError(t, err)
+	require.Len(t, h2.Log.Entries, 2)
+	require.Equal(t, "log-01:log-01:span-01:1", h2.Log.Entries[0].TraceId)
+
+	var harBuf bytes.Buffer
+	require.NoError(t, har.ConvertNDJSONToHAR(bytes.NewReader(buf.Bytes()), &harBuf, &har.Log{Version: har.DefaultVersion, Comment: "converted"}))
+	h3, err := har.ReadHAR(&harBuf)
+	require.NoError(t, err)
+	require.Len(t, h3.Log.Entries, 2)
+	require.Equal(t, "converted", h3.Log.Comment)
+
+	var lineBuf bytes.Buffer
+	require.NoError(t, h3.Write(&harBuf, false))
+	require.NoError(t, har.ConvertHARToNDJSON(&harBuf, &lineBuf))
+	require.Equal(t, buf.String(), lineBuf.String())
+
+	_, err = har.ReadNDJSON(strings.NewReader(lines[0] + "\n{not json\n"))
+	var lineErr *har.LineError
+	require.True(t, errors.As(err, &lineErr))
+	require.Equal(t, 2, lineErr.Line)
+}
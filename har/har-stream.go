@@ -0,0 +1,356 @@
+package har
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+)
+
+// Decoder reads the entries of a har document one at a time, without loading the whole document in memory.
+//
+//	dec := har.NewDecoder(f)
+//	for e, err := range dec.Entries() {
+//	  ...
+//	}
+type Decoder struct {
+	dec    *json.Decoder
+	header map[string]json.RawMessage
+	log    *Log
+	state  decoderState
+
+	// entries of documents older than DefaultVersion are upgraded.
+	upgrade bool
+}
+
+type decoderState int
+
+const (
+	decoderStart decoderState = iota
+	decoderInEntries
+	decoderDone
+)
+
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{dec: json.NewDecoder(r), header: map[string]json.RawMessage{}}
+}
+
+// Header returns the fields of the log other than the entries. Before the end of the entries only the fields preceding
+// them in the document are available. As ReadHAR, documents of older versions are upgraded to DefaultVersion.
+func (d *Decoder) Header() (*Log, error) {
+	if d.state == decoderStart {
+		if err := d.readUntilEntries(); err != nil {
+			return nil, err
+		}
+	}
+
+	if d.log == nil {
+		if err := d.buildHeader(); err != nil {
+			return nil, err
+		}
+
+		if isOlderVersion(d.log.Version) {
+			d.log.upgrade()
+		}
+	}
+
+	return d.log, nil
+}
+
+// Next returns the next entry of the log, io.EOF when the entries are over.
+func (d *Decoder) Next() (*Entry, error) {
+	if d.state == decoderStart {
+		if err := d.readUntilEntries(); err != nil {
+			return nil, err
+		}
+	}
+
+	if d.state == decoderDone {
+		return nil, io.EOF
+	}
+
+	if d.dec.More() {
+		var e Entry
+		if err := d.dec.Decode(&e); err != nil {
+			return nil, err
+		}
+
+		if d.upgrade {
+			e.upgrade()
+		}
+		return &e, nil
+	}
+
+	// the closing bracket of the entries and the fields following them.
+	if err := d.expectDelim(']'); err != nil {
+		return nil, err
+	}
+
+	if err := d.readLogFields(); err != nil {
+		return nil, err
+	}
+
+	d.state, d.log = decoderDone, nil
+	return nil, io.EOF
+}
+
+// Entries iterates over the entries of the log, the iteration stops at the first error.
+func (d *Decoder) Entries() iter.Seq2[*Entry, error] {
+	return func(yield func(*Entry, error) bool) {
+		for {
+			e, err := d.Next()
+			if err == io.EOF {
+				return
+			}
+
+			if !yield(e, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// ForEach calls f for each entry of the log until the end of the entries or the first error.
+func (d *Decoder) ForEach(f func(e *Entry) error) error {
+	for e, err := range d.Entries() {
+		if err != nil {
+			return err
+		}
+
+		if err = f(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readUntilEntries consumes the document up to the opening bracket of the entries, collecting the log fields found on the way.
+func (d *Decoder) readUntilEntries() error {
+	if err := d.expectDelim('{'); err != nil {
+		return err
+	}
+
+	for d.dec.More() {
+		k, err := d.key()
+		if err != nil {
+			return err
+		}
+
+		if k != "log" {
+			var skip json.RawMessage
+			if err = d.dec.Decode(&skip); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err = d.expectDelim('{'); err != nil {
+			return err
+		}
+
+		if err = d.readLogFields(); err != nil {
+			return err
+		}
+
+		if d.state == decoderInEntries {
+			return nil
+		}
+	}
+
+	// no entries in the document.
+	d.state = decoderDone
+	return nil
+}
+
+// readLogFields collects the log fields up to the entries or, if already past them, up to the end of the document.
+func (d *Decoder) readLogFields() error {
+	afterEntries := d.state == decoderInEntries
+	for d.dec.More() {
+		k, err := d.key()
+		if err != nil {
+			return err
+		}
+
+		if k == "entries" && !afterEntries {
+			t, err := d.dec.Token()
+			if err != nil {
+				return err
+			}
+
+			if t == nil {
+				continue
+			}
+
+			if delim, ok := t.(json.Delim); !ok || delim != '[' {
+				return fmt.Errorf("har: entries is not an array")
+			}
+
+			d.state = decoderInEntries
+			return d.prepareUpgrade()
+		}
+
+		var v json.RawMessage
+		if err = d.dec.Decode(&v); err != nil {
+			return err
+		}
+		d.header[k] = v
+	}
+
+	// closing brace of the log: the rest of the document is not of interest.
+	return d.expectDelim('}')
+}
+
+// prepareUpgrade looks at the header fields preceding the entries to tell if the entries have to be upgraded.
+func (d *Decoder) prepareUpgrade() error {
+	if err := d.buildHeader(); err != nil {
+		return err
+	}
+
+	d.upgrade = isOlderVersion(d.log.Version)
+	d.log = nil
+	return nil
+}
+
+func (d *Decoder) buildHeader() error {
+	b, err := json.Marshal(d.header)
+	if err != nil {
+		return err
+	}
+
+	var l Log
+	if err = json.Unmarshal(b, &l); err != nil {
+		return err
+	}
+
+	d.log = &l
+	return nil
+}
+
+func (d *Decoder) key() (string, error) {
+	t, err := d.dec.Token()
+	if err != nil {
+		return "", err
+	}
+
+	k, ok := t.(string)
+	if !ok {
+		return "", fmt.Errorf("har: unexpected token %v", t)
+	}
+	return k, nil
+}
+
+func (d *Decoder) expectDelim(delim json.Delim) error {
+	t, err := d.dec.Token()
+	if err != nil {
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		return err
+	}
+
+	if td, ok := t.(json.Delim); !ok || td != delim {
+		return fmt.Errorf("har: unexpected token %v, expected %v", t, delim)
+	}
+	return nil
+}
+
+// Encoder writes a har document one entry at a time. The document is valid once Close has been called.
+type Encoder struct {
+	w       io.Writer
+	header  *Log
+	suffix  []byte
+	started bool
+	closed  bool
+	entries int
+}
+
+// NewEncoder returns an encoder writing a document with the header fields of the log. The entries of the header
+// are ignored.
+func NewEncoder(w io.Writer, header *Log) *Encoder {
+	if header == nil {
+		header = &Log{}
+	}
+	return &Encoder{w: w, header: header}
+}
+
+var entriesPlaceholder = []byte(`"entries":[]`)
+
+func (enc *Encoder) start() error {
+	if enc.started {
+		return nil
+	}
+
+	l := *enc.header
+	l.Entries = []*Entry{}
+	b, err := json.Marshal(&l)
+	if err != nil {
+		return err
+	}
+
+	ndx := bytes.Index(b, entriesPlaceholder)
+	if ndx < 0 {
+		return errors.New("har: entries not found in the marshalled log")
+	}
+
+	if _, err = enc.w.Write([]byte(`{"log":`)); err != nil {
+		return err
+	}
+
+	if _, err = enc.w.Write(b[:ndx+len(entriesPlaceholder)-1]); err != nil {
+		return err
+	}
+
+	enc.suffix = append([]byte("\n]"), b[ndx+len(entriesPlaceholder):]...)
+	enc.suffix = append(enc.suffix, "}\n"...)
+	enc.started = true
+	return nil
+}
+
+// Encode appends the entry to the document.
+func (enc *Encoder) Encode(e *Entry) error {
+	if enc.closed {
+		return errors.New("har: encoder closed")
+	}
+
+	if err := enc.start(); err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	sep := []byte(",\n")
+	if enc.entries == 0 {
+		sep = sep[1:]
+	}
+
+	if _, err = enc.w.Write(sep); err != nil {
+		return err
+	}
+
+	if _, err = enc.w.Write(b); err != nil {
+		return err
+	}
+
+	enc.entries++
+	return nil
+}
+
+// Close completes the document. The underlying writer is not closed.
+func (enc *Encoder) Close() error {
+	if enc.closed {
+		return nil
+	}
+
+	if err := enc.start(); err != nil {
+		return err
+	}
+
+	enc.closed = true
+	_, err := enc.w.Write(enc.suffix)
+	return err
+}
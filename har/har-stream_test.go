@@ -0,0 +1,69 @@
+package har_test
+
+import (
+	"bytes"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/har"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestStreaming(t *testing.T) {
+	var buf bytes.Buffer
+	enc := har.NewEncoder(&buf, &har.Log{Version: "1.1", Creator: &har.Creator{Name: "test", Version: "1.0"}, Comment: "streamed"})
+	for i := 0; i < 3; i++ {
+		req, err := har.NewRequest(http.MethodPost, "http://localhost:3004/api/v1/orders", []byte(`{"canale":"APPP"}`), http.Header{"Content-Type": []string{"application/json"}}, nil, nil)
+		require.NoError(t, err)
+		require.NoError(t, enc.Encode(&har.Entry{Request: req, Response: har.NewResponse(200, "OK", "application/json", []byte(`{"esito":"OK"}`), nil)}))
+	}
+	require.NoError(t, enc.Close())
+	t.Log(buf.String())
+
+	h, err := har.ReadHAR(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.Len(t, h.Log.Entries, 3)
+	require.Equal(t, "streamed", h.Log.Comment)
+
+	dec := har.NewDecoder(bytes.NewReader(buf.Bytes()))
+	header, err := dec.Header()
+	require.NoError(t, err)
+	require.Equal(t, "test", header.Creator.Name)
+
+	n := 0
+	for e, err := range dec.Entries() {
+		require.NoError(t, err)
+		require.Equal(t, `{"esito":"OK"}`, string(e.Response.Content.Body()))
+		n++
+	}
+	require.Equal(t, 3, n)
+
+	_, err = dec.Next()
+	require.Equal(t, io.EOF, err)
+
+	// the fields following the entries are available at the end.
+	src := `{"log":{"version":"1.2","entries":[{"startedDateTime":"2023-02-12T20:07:02.147874+01:00","time":1}],"comment":"after"}}`
+	dec = har.NewDecoder(strings.NewReader(src))
+	n = 0
+	require.NoError(t, dec.ForEach(func(e *har.Entry) error {
+		n++
+		return nil
+	}))
+	require.Equal(t, 1, n)
+	header, err = dec.Header()
+	require.NoError(t, err)
+	require.Equal(t, "1.2", header.Version)
+	require.Equal(t, "after", header.Comment)
+
+	dec = har.NewDecoder(strings.NewReader(`{"log":{"version":"1.2"}}`))
+	_, err = dec.Next()
+	require.Equal(t, io.EOF, err)
+
+	dec = har.NewDecoder(strings.NewReader(`{"log":{"entries":[{"time":1},`))
+	_, err = dec.Next()
+	require.NoError(t, err)
+	_, err = dec.Next()
+	require.Error(t, err)
+	require.NotEqual(t, io.EOF, err)
+}
@@ -2,11 +2,14 @@ package har
 
 import (
 	"bytes"
+	"encoding/base64"
 	"encoding/json"
 	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-common/util"
 	"github.com/rs/zerolog/log"
 	"io"
 	"net/http"
+	"net/url"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -59,13 +62,14 @@ type CacheData struct {
 //
 // See: https://chromedevtools.github.io/devtools-protocol/tot/HAR#type-Content
 type Content struct {
-	Size        int64  `json:"size" yaml:"size" mapstructure:"size"`                                                    // Length of the returned content in bytes. Should be equal to response.bodySize if there is no compression and bigger when the content has been compressed.
-	Compression int64  `json:"compression,omitempty" yaml:"compression,omitempty" mapstructure:"compression,omitempty"` // Number of bytes saved. Leave out this field if the information is not available.
-	MimeType    string `json:"mimeType" yaml:"mimeType" mapstructure:"mimeType"`                                        // MIME type of the response text (value of the Content-Type response header). The charset attribute of the MIME type is included (if available).
-	Text        string `json:"text,omitempty" yaml:"text,omitempty" mapstructure:"text,omitempty"`                      // Response body sent from the server or loaded from the browser cache. This field is populated with textual content only. The text field is either HTTP decoded text or an encoded (e.g. "base64") representation of the response body. Leave out this field if the information is not available.
-	Encoding    string `json:"encoding,omitempty" yaml:"encoding,omitempty" mapstructure:"encoding,omitempty"`          // Encoding used for response text field e.g "base64". Leave out this field if the text field is HTTP decoded (decompressed & unchunked), than trans-coded from its original character set into UTF-8.
-	Comment     string `json:"comment,omitempty" yaml:"comment,omitempty" mapstructure:"comment,omitempty"`             // A comment provided by the user or the application.
-	Data        []byte `json:"-" yaml:"-" mapstructure:"-"`                                                             // the bytes of the text data...
+	Size        int64      `json:"size" yaml:"size" mapstructure:"size"`                                                    // Length of the returned content in bytes. Should be equal to response.bodySize if there is no compression and bigger when the content has been compressed.
+	Compression int64      `json:"compression,omitempty" yaml:"compression,omitempty" mapstructure:"compression,omitempty"` // Number of bytes saved. Leave out this field if the information is not available.
+	MimeType    string     `json:"mimeType" yaml:"mimeType" mapstructure:"mimeType"`                                        // MIME type of the response text (value of the Content-Type response header). The charset attribute of the MIME type is included (if available).
+	Text        string     `json:"text,omitempty" yaml:"text,omitempty" mapstructure:"text,omitempty"`                      // Response body sent from the server or loaded from the browser cache. This field is populated with textual content only. The text field is either HTTP decoded text or an encoded (e.g. "base64") representation of the response body. Leave out this field if the information is not available.
+	Encoding    string     `json:"encoding,omitempty" yaml:"encoding,omitempty" mapstructure:"encoding,omitempty"`          // Encoding used for response text field e.g "base64". Leave out this field if the text field is HTTP decoded (decompressed & unchunked), than trans-coded from its original character set into UTF-8.
+	Comment     string     `json:"comment,omitempty" yaml:"comment,omitempty" mapstructure:"comment,omitempty"`             // A comment provided by the user or the application.
+	Data        []byte     `json:"-" yaml:"-" mapstructure:"-"`                                                             // the bytes of the text data...
+	Extensions  Extensions `json:"-" yaml:"-" mapstructure:"-"`                                                             // Custom fields not modelled by the struct.
 }
 
 func (c *Content) MarshalJSON() ([]byte, error) {
@@ -73,7 +77,27 @@ func (c *Content) MarshalJSON() ([]byte, error) {
 	if len(c.Data) > 0 {
 		c.Text = string(c.Data)
 	}
-	return json.Marshal((*content)(c))
+	return marshalWithExtensions((*content)(c), c.Extensions)
+}
+
+// Body returns the bytes of the content: Data if available, otherwise the Text decoded according to the Encoding.
+func (c *Content) Body() []byte {
+	if len(c.Data) > 0 {
+		return c.Data
+	}
+
+	if c.Encoding == "base64" {
+		b, err := base64.StdEncoding.DecodeString(c.Text)
+		if err == nil {
+			return b
+		}
+	}
+
+	if c.Text == "" {
+		return nil
+	}
+
+	return []byte(c.Text)
 }
 
 // Cookie contains list of all cookies (used in [Request] and [Response]
@@ -124,6 +148,7 @@ type Entry struct {
 	Comment         string                            `json:"comment,omitempty" yaml:"comment,omitempty" mapstructure:"comment,omitempty"`                         // A comment provided by the user or the application.
 	PII             PersonallyIdentifiableInformation `json:"_pii,omitempty"`                                                                                      // Extension field to identify sensistive information handling for logging
 	TraceId         string                            `json:"_trace-id,omitempty" yaml:"_trace-id,omitempty" mapstructure:"_trace-id,omitempty"`
+	Extensions      Extensions                        `json:"-" yaml:"-" mapstructure:"-"` // Custom fields not modelled by the struct (e.g. _initiator, _resourceType).
 }
 
 func (e *Entry) MaskRequestBody(jm PIIMasker) error {
@@ -176,6 +201,8 @@ type Log struct {
 	Entries []*Entry `json:"entries" yaml:"entries" mapstructure:"entries"`                               // List of all exported (tracked) requests.
 	Comment string   `json:"comment,omitempty" yaml:"comment,omitempty" mapstructure:"comment,omitempty"` // A comment provided by the user or the application.
 	TraceId string   `json:"_trace-id,omitempty" yaml:"_trace-id,omitempty" mapstructure:"_trace-id,omitempty"`
+
+	Extensions Extensions `json:"-" yaml:"-" mapstructure:"-"` // Custom fields not modelled by the struct.
 }
 
 func (log *Log) FindEarliestStartedDateTime() string {
@@ -211,6 +238,23 @@ func (nvs NameValuePairs) GetFirst(n string) NameValuePair {
 	return NameValuePair{}
 }
 
+// NameValuePairsFromValues converts url values (e.g. a parsed query string) in pairs sorted by name.
+func NameValuePairsFromValues(values url.Values) NameValuePairs {
+	names := make([]string, 0, len(values))
+	for n := range values {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	nvs := NameValuePairs{}
+	for _, n := range names {
+		for _, v := range values[n] {
+			nvs = append(nvs, NameValuePair{Name: n, Value: v})
+		}
+	}
+	return nvs
+}
+
 // Page represents list of exported pages.
 //
 // See: https://chromedevtools.github.io/devtools-protocol/tot/HAR#type-Page
@@ -283,6 +327,19 @@ func (po *PostData) MarshalJSON() ([]byte, error) {
 	return json.Marshal((*postdata)(po))
 }
 
+// Body returns the bytes of the posted data: Data if available, otherwise the Text.
+func (po *PostData) Body() []byte {
+	if len(po.Data) > 0 {
+		return po.Data
+	}
+
+	if po.Text == "" {
+		return nil
+	}
+
+	return []byte(po.Text)
+}
+
 // Request contains detailed info about performed request.
 //
 // See: https://chromedevtools.github.io/devtools-protocol/tot/HAR#type-Request
@@ -292,12 +349,13 @@ type Request struct {
 	PathParams  []Param        `json:"_pathParams,omitempty" yaml:"_pathParams,omitempty" mapstructure:"_pathParams,omitempty"` // Absolute URL of the request (fragments are not included).
 	HTTPVersion string         `json:"httpVersion,omitempty" yaml:"httpVersion,omitempty" mapstructure:"httpVersion,omitempty"` // Request HTTP Version.
 	Cookies     []Cookie       `json:"cookies" yaml:"cookies" mapstructure:"cookies"`                                           // List of cookie objects.
-	Headers     NameValuePairs `json:"headers,omitempty" yaml:"headers,omitempty" mapstructure:"headers,omitempty"`             // List of header objects.
+	Headers     NameValuePairs `json:"headers" yaml:"headers" mapstructure:"headers"`                                           // List of header objects.
 	QueryString NameValuePairs `json:"queryString" yaml:"queryString" mapstructure:"queryString"`                               // List of query parameter objects.
 	PostData    *PostData      `json:"postData,omitempty" yaml:"postData,omitempty" mapstructure:"postData,omitempty"`          // Posted data info.
 	HeadersSize int64          `json:"headersSize" yaml:"headersSize" mapstructure:"headersSize"`                               // Total number of bytes from the start of the HTTP request message until (and including) the double CRLF before the body. Set to -1 if the info is not available.
 	BodySize    int64          `json:"bodySize" yaml:"bodySize" mapstructure:"bodySize"`                                        // Size of the request body (POST data payload) in bytes. Set to -1 if the info is not available.
 	Comment     string         `json:"comment,omitempty" yaml:"comment,omitempty" mapstructure:"comment,omitempty"`             // A comment provided by the user or the application.
+	Extensions  Extensions     `json:"-" yaml:"-" mapstructure:"-"`                                                             // Custom fields not modelled by the struct.
 }
 
 type UrlBuilder struct {
@@ -419,11 +477,16 @@ func (req *Request) SetHeader(n string, v string) {
 	})
 }
 
-func NewRequestFromHttpRequest(req *http.Request) (*Request, error) {
+func NewRequestFromHttpRequest(req *http.Request, opts ...ConversionOption) (*Request, error) {
 	const semLogContext = "http-archive::har-request-from-http-request"
 	var err error
 
-	ct := "appication/octet-stream"
+	options := conversionOpts{}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	ct := "application/octet-stream"
 	headers := make([]NameValuePair, 0)
 	for n, h := range req.Header {
 		for _, v := range h {
@@ -449,11 +512,25 @@ func NewRequestFromHttpRequest(req *http.Request) (*Request, error) {
 		req.Body = io.NopCloser(bytes.NewReader(bodyContent))
 	}
 
+	method := req.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	proto := req.Proto
+	if proto == "" {
+		proto = "HTTP/1.1"
+	}
+
+	u := absoluteURL(req)
 	r := &Request{
-		HTTPVersion: "1.1",
-		HeadersSize: -1,
+		Method:      method,
+		URL:         u.String(),
+		HTTPVersion: proto,
+		HeadersSize: requestHeadersSize(req, method, proto),
 		Headers:     headers,
-		Cookies:     []Cookie{},
+		QueryString: NameValuePairsFromValues(u.Query()),
+		Cookies:     addJarCookies(RequestCookies(req.Header), options.jar, u),
 		BodySize:    int64(len(bodyContent)),
 		PostData: &PostData{
 			MimeType: ct,
@@ -461,53 +538,44 @@ func NewRequestFromHttpRequest(req *http.Request) (*Request, error) {
 		},
 	}
 
+	if err = r.PostData.ParseParams(options.params...); err != nil {
+		log.Warn().Err(err).Msg(semLogContext + " unparsable form params")
+	}
+
 	return r, nil
 }
 
 // NewRequest introduced when migrating the tpm-symphony. Revised the implementation to take care of different postData build
 func NewRequest(method string, url string, body []byte, headers http.Header, queryParams NameValuePairs, params []Param) (*Request, error) {
+	const semLogContext = "http-archive::new-request"
 
 	ct := headers.Get("content-type")
 
 	if queryParams == nil {
 		queryParams = NameValuePairs{}
 	}
-	var hs []NameValuePair
+	hs := make([]NameValuePair, 0)
 	for n, h := range headers {
 		for i := range h {
 			hs = append(hs, NameValuePair{Name: n, Value: h[i]})
 		}
 	}
 
+	// any method may carry a body: the post data is set whenever there is something to describe.
 	var postData *PostData
-	bodySize := -1
-
-	switch method {
-	case http.MethodGet:
-		if len(params) != 0 {
-			postData = &PostData{
-				MimeType: "",
-				Data:     nil,
-				Params:   params,
-			}
+	if len(params) != 0 || len(body) > 0 {
+		postData = &PostData{
+			MimeType: ct,
+			Data:     body,
+			Params:   params,
 		}
-	case http.MethodPut:
-		fallthrough
-	case http.MethodPost:
-		if len(params) != 0 || len(body) > 0 {
-			if len(body) > 0 {
-				bodySize = len(body)
-			}
 
-			if params == nil {
-				params = make([]Param, 0)
-			}
+		if postData.Params == nil {
+			postData.Params = make([]Param, 0)
+		}
 
-			postData = &PostData{
-				MimeType: ct,
-				Data:     body,
-				Params:   params,
-			}
+		if err := postData.ParseParams(); err != nil {
+			log.Warn().Err(err).Msg(semLogContext + " unparsable form params")
 		}
 	}
 
@@ -515,12 +583,12 @@ func NewRequest(method string, url string, body []byte, headers http.Header, que
 		Method:      method,
 		URL:         url,
 		PathParams:  params,
-		HTTPVersion: "1.1",
+		HTTPVersion: "HTTP/1.1",
 		Headers:     hs,
-		HeadersSize: -1,
-		Cookies:     []Cookie{},
+		HeadersSize: urlHeadersSize(method, url, "HTTP/1.1", headers, int64(len(body))),
+		Cookies:     RequestCookies(headers),
 		QueryString: queryParams,
-		BodySize:    int64(bodySize),
+		BodySize:    int64(len(body)),
 		PostData:    postData,
 	}
 
@@ -541,17 +609,23 @@ type Response struct {
 	HeadersSize int            `json:"headersSize" yaml:"headersSize" mapstructure:"headersSize"`                               // Total number of bytes from the start of the HTTP response message until (and including) the double CRLF before the body. Set to -1 if the info is not available.
 	BodySize    int64          `json:"bodySize" yaml:"bodySize" mapstructure:"bodySize"`                                        // Size of the received response body in bytes. Set to zero in case of responses coming from the cache (304). Set to -1 if the info is not available.
 	Comment     string         `json:"comment,omitempty" yaml:"comment,omitempty" mapstructure:"comment,omitempty"`             // A comment provided by the user or the application.
+	Extensions  Extensions     `json:"-" yaml:"-" mapstructure:"-"`                                                             // Custom fields not modelled by the struct (e.g. _transferSize).
 }
 
 func (resp *Response) HasBody() bool {
 	return resp.Content != nil && len(resp.Content.Data) > 0
 }
 
-func NewResponseFromHttpResponse(resp *http.Response) (*Response, error) {
+func NewResponseFromHttpResponse(resp *http.Response, opts ...ConversionOption) (*Response, error) {
 	const semLogContext = "http-archive::har-response-from-http-response"
 	var err error
 
-	ct := "appication/octet-stream"
+	options := conversionOpts{}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	ct := "application/octet-stream"
 	headers := make([]NameValuePair, 0)
 	for n, h := range resp.Header {
 		for _, v := range h {
@@ -583,7 +657,7 @@ func NewResponseFromHttpResponse(resp *http.Response) (*Response, error) {
 		StatusText:  resp.Status,
 		HeadersSize: -1,
 		Headers:     headers,
-		Cookies:     []Cookie{},
+		Cookies:     ResponseCookies(resp.Header),
 		BodySize:    int64(len(bodyContent)),
 		Content: &Content{
 			MimeType: ct,
@@ -592,6 +666,12 @@ func NewResponseFromHttpResponse(resp *http.Response) (*Response, error) {
 		},
 	}
 
+	if ce := resp.Header.Get("Content-Encoding"); options.decodeContent && ce != "" {
+		if err = r.decodeContent(ce, options.maxDecodedSize); err != nil {
+			log.Warn().Err(err).Str("content-encoding", ce).Msg(semLogContext + " body left encoded")
+		}
+	}
+
 	return r, nil
 }
 
@@ -609,7 +689,7 @@ func NewResponse(sc int, sTest string, mimeType string, body []byte, headers Nam
 		StatusText:  sTest,
 		HeadersSize: -1,
 		Headers:     headers,
-		Cookies:     []Cookie{},
+		Cookies:     ResponseCookies(headers.httpHeader()),
 		BodySize:    int64(len(body)),
 		Content: &Content{
 			MimeType: mimeType,
@@ -6,6 +6,7 @@ import (
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/stretchr/testify/require"
+	"net/http"
 	"os"
 	"testing"
 )
@@ -163,3 +164,75 @@ func TestHarLog(t *testing.T) {
 
 	t.Log(string(b))
 }
+
+func TestNewRequest(t *testing.T) {
+	jsonHeader := http.Header{"Content-Type": []string{"application/json"}}
+	body := []byte(`{"canale":"APPP"}`)
+
+	tests := []struct {
+		name         string
+		method       string
+		body         []byte
+		params       []har.Param
+		wantPostData bool
+	}{
+		{name: "get", method: http.MethodGet},
+		{name: "get-with-body", method: http.MethodGet, body: body, wantPostData: true},
+		{name: "get-with-params", method: http.MethodGet, params: []har.Param{{Name: "id", Value: "1"}}, wantPostData: true},
+		{name: "head", method: http.MethodHead},
+		{name: "options", method: http.MethodOptions},
+		{name: "post", method: http.MethodPost, body: body, wantPostData: true},
+		{name: "post-empty", method: http.MethodPost},
+		{name: "put", method: http.MethodPut, body: body, wantPostData: true},
+		{name: "patch", method: http.MethodPatch, body: body, wantPostData: true},
+		{name: "delete", method: http.MethodDelete, body: body, wantPostData: true},
+		{name: "delete-empty", method: http.MethodDelete},
+		{name: "trace", method: http.MethodTrace},
+		{name: "connect", method: http.MethodConnect},
+		{name: "custom", method: "PURGE", body: body, wantPostData: true},
+		{name: "custom-lowercase", method: "report", body: body, wantPostData: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := har.NewRequest(tt.method, "http://localhost:3004/api/v1/orders?lang=it", tt.body, jsonHeader, nil, tt.params)
+			require.NoError(t, err)
+			require.Equal(t, tt.method, req.Method)
+			require.Equal(t, int64(len(tt.body)), req.BodySize)
+
+			// request line, Host: localhost:3004, Content-Type: application/json, Content-Length when there is a body and the empty line.
+			hs := int64(len(tt.method) + len(" /api/v1/orders?lang=it HTTP/1.1\r\n") + 22 + 32 + 2)
+			if len(tt.body) > 0 {
+				hs += 20
+			}
+			require.Equal(t, hs, req.HeadersSize)
+
+			if !tt.wantPostData {
+				require.Nil(t, req.PostData)
+				return
+			}
+
+			require.NotNil(t, req.PostData)
+			require.Equal(t, tt.body, req.PostData.Body())
+			require.Equal(t, "application/json", req.PostData.MimeType)
+			require.NotNil(t, req.PostData.Params)
+
+			httpReq, err := req.ToHttpRequest(t.Context())
+			require.NoError(t, err)
+			require.Equal(t, int64(len(tt.body)), httpReq.ContentLength)
+		})
+	}
+}
+
+func TestEntryClone(t *testing.T) {
+	e := harLog.Log.Entries[0]
+	c := e.Clone()
+	require.Equal(t, e, c)
+
+	c.Request.Headers[0].Value = "changed"
+	c.Response.Content.Comment = "changed"
+	c.Timings.Wait = -1
+	require.NotEqual(t, "changed", e.Request.Headers[0].Value)
+	require.NotEqual(t, "changed", e.Response.Content.Comment)
+	require.NotEqual(t, float64(-1), e.Timings.Wait)
+}
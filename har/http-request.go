@@ -0,0 +1,297 @@
+package har
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// skippedRequestHeaders are not copied when rebuilding an http request: they are either computed by the transport or
+// refer to the original connection.
+var skippedRequestHeaders = map[string]struct{}{
+	"content-length":    {},
+	"transfer-encoding": {},
+	"connection":        {},
+	"keep-alive":        {},
+	"upgrade":           {},
+	"te":                {},
+	"trailer":           {},
+}
+
+type conversionOpts struct {
+	jar            http.CookieJar
+	params         []ParamsOption
+	decodeContent  bool
+	maxDecodedSize int64
+}
+
+// ConversionOption customizes the conversion of http requests and responses.
+type ConversionOption func(o *conversionOpts)
+
+// ToHttpRequest rebuilds the http request described by the har request: method, url, headers, cookies, query string and body.
+// The query string is added to the url when the latter doesn't carry one. The body is taken from the postData text or, if missing,
+// built from the postData params for url-encoded and multipart mime types.
+func (req *Request) ToHttpRequest(ctx context.Context) (*http.Request, error) {
+
+	u, err := url.Parse(req.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.RawQuery == "" && len(req.QueryString) > 0 {
+		q := url.Values{}
+		for _, nv := range req.QueryString {
+			q.Add(nv.Name, nv.Value)
+		}
+		u.RawQuery = q.Encode()
+	}
+
+	method := req.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	body, ct, err := req.httpBody()
+	if err != nil {
+		return nil, err
+	}
+
+	var bodyReader io.Reader
+	if len(body) > 0 {
+		bodyReader = bytes.NewReader(body)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, method, u.String(), bodyReader)
+	if err != nil {
+		return nil, err
+	}
+
+	hasCookieHeader := false
+	for _, h := range req.Headers {
+		n := strings.ToLower(h.Name)
+		if _, ok := skippedRequestHeaders[n]; ok || strings.HasPrefix(n, ":") {
+			continue
+		}
+
+		switch n {
+		case "host":
+			httpReq.Host = h.Value
+		case "cookie":
+			hasCookieHeader = true
+			httpReq.Header.Add(h.Name, h.Value)
+		default:
+			httpReq.Header.Add(h.Name, h.Value)
+		}
+	}
+
+	if ct != "" {
+		httpReq.Header.Set("Content-Type", ct)
+	}
+
+	if !hasCookieHeader {
+		for _, c := range req.Cookies {
+			httpReq.AddCookie(&http.Cookie{Name: c.Name, Value: c.Value})
+		}
+	}
+
+	return httpReq, nil
+}
+
+// httpBody returns the body and, when the body has been rebuilt from the params, the content type to be used.
+func (req *Request) httpBody() ([]byte, string, error) {
+	if req.PostData == nil {
+		return nil, "", nil
+	}
+
+	if b := req.PostData.Body(); len(b) > 0 {
+		return b, "", nil
+	}
+
+	if len(req.PostData.Params) == 0 {
+		return nil, "", nil
+	}
+
+	mt, _, _ := mime.ParseMediaType(req.PostData.MimeType)
+	switch mt {
+	case "application/x-www-form-urlencoded":
+		form := url.Values{}
+		for _, p := range req.PostData.Params {
+			form.Add(p.Name, p.Value)
+		}
+		return []byte(form.Encode()), "", nil
+
+	case "multipart/form-data":
+		var buf bytes.Buffer
+		mw := multipart.NewWriter(&buf)
+		for _, p := range req.PostData.Params {
+			var err error
+			if p.FileName != "" {
+				err = writeMultipartFile(mw, p)
+			} else {
+				err = mw.WriteField(p.Name, p.Value)
+			}
+
+			if err != nil {
+				return nil, "", err
+			}
+		}
+
+		if err := mw.Close(); err != nil {
+			return nil, "", err
+		}
+
+		// the boundary of the original request is lost, the content type has to reflect the new one.
+		return buf.Bytes(), mw.FormDataContentType(), nil
+	}
+
+	return nil, "", nil
+}
+
+func writeMultipartFile(mw *multipart.Writer, p Param) error {
+	ct := p.ContentType
+	if ct == "" {
+		ct = "application/octet-stream"
+	}
+
+	h := make(map[string][]string)
+	h["Content-Disposition"] = []string{fmt.Sprintf(`form-data; name="%s"; filename="%s"`, escapeQuotes(p.Name), escapeQuotes(p.FileName))}
+	h["Content-Type"] = []string{ct}
+	w, err := mw.CreatePart(h)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write([]byte(p.Value))
+	return err
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func escapeQuotes(s string) string {
+	return quoteEscaper.Replace(s)
+}
+
+// absoluteURL returns the url of the request. Server side requests carry the path only: scheme and host are taken from the
+// Forwarded header, the X-Forwarded-Proto and X-Forwarded-Host headers or, missing those, the connection and the Host header.
+func absoluteURL(req *http.Request) *url.URL {
+	u := &url.URL{}
+	if req.URL != nil {
+		*u = *req.URL
+	}
+
+	if u.IsAbs() && u.Host != "" {
+		return u
+	}
+
+	proto, host := forwardedProtoHost(req.Header)
+	switch {
+	case proto != "":
+		u.Scheme = strings.ToLower(proto)
+	case req.TLS != nil:
+		u.Scheme = "https"
+	default:
+		u.Scheme = HttpScheme
+	}
+
+	switch {
+	case host != "":
+		u.Host = host
+	case req.Host != "":
+		u.Host = req.Host
+	case u.Host == "":
+		u.Host = Localhost
+	}
+
+	if u.Path == "" {
+		u.Path = "/"
+	}
+
+	return u
+}
+
+// forwardedProtoHost returns the proto and host of the first hop of the Forwarded header (RFC 7239), falling back to
+// the X-Forwarded-Proto and X-Forwarded-Host headers.
+func forwardedProtoHost(h http.Header) (string, string) {
+	var proto, host string
+	if fwd := h.Get("Forwarded"); fwd != "" {
+		first, _, _ := strings.Cut(fwd, ",")
+		for _, pair := range strings.Split(first, ";") {
+			k, v, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if !ok {
+				continue
+			}
+
+			v = strings.Trim(v, `"`)
+			switch strings.ToLower(k) {
+			case "proto":
+				proto = v
+			case "host":
+				host = v
+			}
+		}
+	}
+
+	if proto == "" {
+		proto, _, _ = strings.Cut(h.Get("X-Forwarded-Proto"), ",")
+		proto = strings.TrimSpace(proto)
+	}
+
+	if host == "" {
+		host, _, _ = strings.Cut(h.Get("X-Forwarded-Host"), ",")
+		host = strings.TrimSpace(host)
+	}
+
+	return proto, host
+}
+
+// requestHeadersSize computes the size of the request line and of the headers, host included, up to the empty line before the body.
+func requestHeadersSize(req *http.Request, method, proto string) int64 {
+	uri := req.RequestURI
+	if uri == "" && req.URL != nil {
+		uri = req.URL.RequestURI()
+	}
+
+	host := req.Host
+	if host == "" && req.URL != nil {
+		host = req.URL.Host
+	}
+
+	return headersSize(method, uri, proto, host, req.Header, req.ContentLength)
+}
+
+// urlHeadersSize is the same as requestHeadersSize for a request known by its url.
+func urlHeadersSize(method, rawURL, proto string, h http.Header, contentLength int64) int64 {
+	uri, host := rawURL, ""
+	if u, err := url.Parse(rawURL); err == nil {
+		uri, host = u.RequestURI(), u.Host
+	}
+
+	return headersSize(method, uri, proto, host, h, contentLength)
+}
+
+// headersSize counts the bytes of the request line and of the headers, adding Host and Content-Length when they are set by the transport.
+func headersSize(method, uri, proto, host string, h http.Header, contentLength int64) int64 {
+	size := len(method) + 1 + len(uri) + 1 + len(proto) + 2
+	if host != "" && h.Get("Host") == "" {
+		size += len("Host: ") + len(host) + 2
+	}
+
+	for n, vs := range h {
+		for _, v := range vs {
+			size += len(n) + 2 + len(v) + 2
+		}
+	}
+
+	if contentLength > 0 && h.Get("Content-Length") == "" {
+		size += len("Content-Length: ") + len(strconv.FormatInt(contentLength, 10)) + 2
+	}
+
+	return int64(size + 2)
+}
@@ -0,0 +1,48 @@
+package har_test
+
+import (
+	"bytes"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/har"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewRequestFromHttpRequest(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/items?q=a&page=2", bytes.NewReader([]byte(`{"a":1}`)))
+	req.Header.Set("Content-Type", "application/json")
+
+	harReq, err := har.NewRequestFromHttpRequest(req)
+	require.NoError(t, err)
+	require.Equal(t, http.MethodPost, harReq.Method)
+	require.Equal(t, "http://example.com/api/v1/items?q=a&page=2", harReq.URL)
+	require.Equal(t, "HTTP/1.1", harReq.HTTPVersion)
+	require.Equal(t, har.NameValuePairs{{Name: "page", Value: "2"}, {Name: "q", Value: "a"}}, harReq.QueryString)
+	require.Equal(t, "application/json", harReq.PostData.MimeType)
+
+	// request line, Host: example.com, Content-Type: application/json, Content-Length: 7 and the empty line.
+	require.Equal(t, int64(40+19+32+19+2), harReq.HeadersSize)
+
+	req = httptest.NewRequest(http.MethodGet, "/items", nil)
+	req.Header.Set("X-Forwarded-Proto", "https")
+	req.Header.Set("X-Forwarded-Host", "api.example.com, proxy.local")
+	harReq, err = har.NewRequestFromHttpRequest(req)
+	require.NoError(t, err)
+	require.Equal(t, "https://api.example.com/items", harReq.URL)
+	require.Equal(t, "application/octet-stream", harReq.PostData.MimeType)
+
+	req.Header.Set("Forwarded", `for=192.0.2.60;proto=http;host="public.example.com", for=198.51.100.17`)
+	harReq, err = har.NewRequestFromHttpRequest(req)
+	require.NoError(t, err)
+	require.Equal(t, "http://public.example.com/items", harReq.URL)
+
+	req, err = http.NewRequest(http.MethodDelete, "https://example.org:8443/items/1", nil)
+	require.NoError(t, err)
+	req.Proto = "HTTP/2.0"
+	harReq, err = har.NewRequestFromHttpRequest(req)
+	require.NoError(t, err)
+	require.Equal(t, "https://example.org:8443/items/1", harReq.URL)
+	require.Equal(t, "HTTP/2.0", harReq.HTTPVersion)
+	require.Empty(t, harReq.QueryString)
+}
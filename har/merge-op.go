@@ -12,15 +12,39 @@ func (h *HAR) Merge(another *HAR, compFunction func(e1, e2 *Entry) bool) (*HAR,
 		return compFunction(entries[p], entries[q])
 	})
 
+	// pages are kept so that the entries keep referring to them.
+	var pages []*Page
+	seen := map[string]bool{}
+	for _, p := range append(append([]*Page{}, h.Log.Pages...), another.Log.Pages...) {
+		if p != nil && !seen[p.ID] {
+			seen[p.ID] = true
+			pages = append(pages, p)
+		}
+	}
+
+	// custom fields of the log: the ones of h take precedence.
+	var x Extensions
+	for _, src := range []Extensions{h.Log.Extensions, another.Log.Extensions} {
+		for k, v := range src {
+			if _, ok := x[k]; !ok {
+				if x == nil {
+					x = Extensions{}
+				}
+				x[k] = v
+			}
+		}
+	}
+
 	merged := HAR{
 		Log: &Log{
-			Version: h.Log.Version,
-			Creator: h.Log.Creator,
-			Browser: h.Log.Browser,
-			Pages:   nil,
-			Entries: entries,
-			Comment: h.Log.Comment,
-			TraceId: h.Log.TraceId,
+			Version:    h.Log.Version,
+			Creator:    h.Log.Creator,
+			Browser:    h.Log.Browser,
+			Pages:      pages,
+			Entries:    entries,
+			Comment:    h.Log.Comment,
+			TraceId:    h.Log.TraceId,
+			Extensions: x,
 		},
 	}
 
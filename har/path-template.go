@@ -0,0 +1,63 @@
+package har
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const PathParamPlaceholder = "{id}"
+
+var (
+	numericIdRegexp = regexp.MustCompile(`^\d+$`)
+	uuidRegexp      = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	hexIdRegexp     = regexp.MustCompile(`^[0-9a-fA-F]{16,}$`)
+	mixedIdRegexp   = regexp.MustCompile(`^[A-Za-z0-9]*\d[A-Za-z0-9]*$`)
+)
+
+// IsPathIdSegment tells if a path segment looks like an identifier: numbers, uuids, long hex strings
+// and alphanumeric tokens (no separators) longer than 3 characters and made of digits for at least a half,
+// or longer than 11 characters with at least 3 digits.
+func IsPathIdSegment(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	if numericIdRegexp.MatchString(s) || uuidRegexp.MatchString(s) || hexIdRegexp.MatchString(s) {
+		return true
+	}
+
+	if !mixedIdRegexp.MatchString(s) {
+		return false
+	}
+
+	digits := 0
+	for _, c := range s {
+		if c >= '0' && c <= '9' {
+			digits++
+		}
+	}
+
+	return (len(s) >= 4 && digits*2 >= len(s)) || (len(s) >= 12 && digits >= 3)
+}
+
+// PathTemplate replaces the segments of the path looking like identifiers with PathParamPlaceholder.
+func PathTemplate(p string) string {
+	segs := strings.Split(p, "/")
+	for i, s := range segs {
+		if IsPathIdSegment(s) {
+			segs[i] = PathParamPlaceholder
+		}
+	}
+	return strings.Join(segs, "/")
+}
+
+// URLTemplate returns the method and the templated path of the request, e.g. "GET /api/v1/items/{id}".
+func (req *Request) URLTemplate() string {
+	p := req.URL
+	if u, err := url.Parse(req.URL); err == nil {
+		p = u.Path
+	}
+
+	return strings.ToUpper(req.Method) + " " + PathTemplate(p)
+}
@@ -0,0 +1,170 @@
+package har
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	DefaultMaxParamsBodySize = 1 << 20
+	DefaultMaxParamFileSize  = 64 << 10
+)
+
+type paramsOpts struct {
+	maxBodySize     int64
+	maxFileSize     int64
+	omitFileContent bool
+}
+
+type ParamsOption func(o *paramsOpts)
+
+// WithMaxParamsBodySize sets the size over which the body is not parsed into params. Zero or negative disables the limit.
+func WithMaxParamsBodySize(n int64) ParamsOption {
+	return func(o *paramsOpts) {
+		o.maxBodySize = n
+	}
+}
+
+// WithMaxParamFileSize sets the size over which the content of a posted file is left out of the param value. Zero or negative disables the limit.
+func WithMaxParamFileSize(n int64) ParamsOption {
+	return func(o *paramsOpts) {
+		o.maxFileSize = n
+	}
+}
+
+// OmitFileContent leaves out the content of every posted file: the params keep name, file name and content type only.
+func OmitFileContent(b bool) ParamsOption {
+	return func(o *paramsOpts) {
+		o.omitFileContent = b
+	}
+}
+
+// WithParamsOptions sets the options used to parse the posted form params.
+func WithParamsOptions(opts ...ParamsOption) ConversionOption {
+	return func(o *conversionOpts) {
+		o.params = append(o.params, opts...)
+	}
+}
+
+// ParseParams fills the params from the body when the mime type is application/x-www-form-urlencoded or multipart/form-data.
+// The order of the fields is preserved. Posted files whose content is omitted, because of the options or because not valid utf-8,
+// carry a comment with the size of the content; in that case the raw body is dropped as well not to keep the content in the text.
+func (po *PostData) ParseParams(opts ...ParamsOption) error {
+	options := paramsOpts{maxBodySize: DefaultMaxParamsBodySize, maxFileSize: DefaultMaxParamFileSize}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	mt, mtParams, err := mime.ParseMediaType(po.MimeType)
+	if err != nil || (mt != "application/x-www-form-urlencoded" && mt != "multipart/form-data") {
+		return nil
+	}
+
+	body := po.Body()
+	if len(body) == 0 {
+		return nil
+	}
+
+	if options.maxBodySize > 0 && int64(len(body)) > options.maxBodySize {
+		return nil
+	}
+
+	var params []Param
+	var omitted bool
+	if mt == "application/x-www-form-urlencoded" {
+		params, err = parseUrlEncodedParams(string(body))
+	} else {
+		params, omitted, err = parseMultipartParams(body, mtParams["boundary"], &options)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	po.Params = params
+	if omitted {
+		po.Data = nil
+		po.Text = ""
+	}
+	return nil
+}
+
+func parseUrlEncodedParams(s string) ([]Param, error) {
+	params := make([]Param, 0)
+	for _, field := range strings.Split(s, "&") {
+		if field == "" {
+			continue
+		}
+
+		n, v, _ := strings.Cut(field, "=")
+		n, err := url.QueryUnescape(n)
+		if err != nil {
+			return nil, err
+		}
+
+		v, err = url.QueryUnescape(v)
+		if err != nil {
+			return nil, err
+		}
+
+		params = append(params, Param{Name: n, Value: v})
+	}
+
+	return params, nil
+}
+
+// parseMultipartParams returns the params of the body and whether the content of any posted file has been omitted.
+func parseMultipartParams(body []byte, boundary string, options *paramsOpts) ([]Param, bool, error) {
+	if boundary == "" {
+		return nil, false, errors.New("multipart boundary is missing")
+	}
+
+	params := make([]Param, 0)
+	omitted := false
+	mr := multipart.NewReader(bytes.NewReader(body), boundary)
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			return params, omitted, nil
+		}
+
+		if err != nil {
+			return nil, false, err
+		}
+
+		content, err := io.ReadAll(part)
+		_ = part.Close()
+		if err != nil {
+			return nil, false, err
+		}
+
+		p := Param{Name: part.FormName(), FileName: part.FileName()}
+		if p.FileName == "" {
+			p.Value = string(content)
+			params = append(params, p)
+			continue
+		}
+
+		p.ContentType = part.Header.Get("Content-Type")
+		switch {
+		case options.omitFileContent:
+			p.Comment = fmt.Sprintf("content of %d bytes omitted", len(content))
+		case options.maxFileSize > 0 && int64(len(content)) > options.maxFileSize:
+			p.Comment = fmt.Sprintf("content of %d bytes omitted: exceeds the limit of %d bytes", len(content), options.maxFileSize)
+		case !utf8.Valid(content):
+			p.Comment = fmt.Sprintf("binary content of %d bytes omitted", len(content))
+		default:
+			p.Value = string(content)
+		}
+
+		omitted = omitted || p.Comment != ""
+		params = append(params, p)
+	}
+}
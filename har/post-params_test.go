@@ -0,0 +1,74 @@
+package har_test
+
+import (
+	"bytes"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/har"
+	"github.com/stretchr/testify/require"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"testing"
+)
+
+func TestParseParams(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader("user=mario+rossi&pwd=a%26b&remember"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	harReq, err := har.NewRequestFromHttpRequest(req)
+	require.NoError(t, err)
+	require.Equal(t, []har.Param{{Name: "user", Value: "mario rossi"}, {Name: "pwd", Value: "a&b"}, {Name: "remember"}}, harReq.PostData.Params)
+	require.Equal(t, "user=mario+rossi&pwd=a%26b&remember", string(harReq.PostData.Body()), "the raw body is kept")
+
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	require.NoError(t, mw.WriteField("description", "a report"))
+	writePart(t, mw, "report", "report.csv", "text/csv", []byte("a,b\n1,2\n"))
+	writePart(t, mw, "image", "logo.png", "image/png", []byte{0x89, 'P', 'N', 'G', 0xff, 0xfe})
+	writePart(t, mw, "big", "big.txt", "text/plain", bytes.Repeat([]byte("x"), 100))
+	require.NoError(t, mw.Close())
+
+	req = httptest.NewRequest(http.MethodPost, "/upload", bytes.NewReader(buf.Bytes()))
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	harReq, err = har.NewRequestFromHttpRequest(req, har.WithParamsOptions(har.WithMaxParamFileSize(50)))
+	require.NoError(t, err)
+	require.Equal(t, []har.Param{
+		{Name: "description", Value: "a report"},
+		{Name: "report", Value: "a,b\n1,2\n", FileName: "report.csv", ContentType: "text/csv"},
+		{Name: "image", FileName: "logo.png", ContentType: "image/png", Comment: "binary content of 6 bytes omitted"},
+		{Name: "big", FileName: "big.txt", ContentType: "text/plain", Comment: "content of 100 bytes omitted: exceeds the limit of 50 bytes"},
+	}, harReq.PostData.Params)
+	require.Empty(t, harReq.PostData.Body(), "the raw body is dropped with the omitted files")
+	require.Equal(t, int64(buf.Len()), harReq.BodySize)
+
+	pd := har.PostData{MimeType: mw.FormDataContentType(), Data: buf.Bytes()}
+	require.NoError(t, pd.ParseParams(har.OmitFileContent(true)))
+	require.Equal(t, "a report", pd.Params[0].Value)
+	require.Empty(t, pd.Params[1].Value)
+	require.Equal(t, "report.csv", pd.Params[1].FileName)
+	require.Empty(t, pd.Text)
+	require.Nil(t, pd.Data)
+
+	pd = har.PostData{MimeType: mw.FormDataContentType(), Data: buf.Bytes()}
+	require.NoError(t, pd.ParseParams(har.WithMaxParamsBodySize(10)))
+	require.Empty(t, pd.Params, "bodies over the limit are not parsed")
+
+	pd = har.PostData{MimeType: "application/json", Data: []byte(`{"a":1}`)}
+	require.NoError(t, pd.ParseParams())
+	require.Empty(t, pd.Params)
+
+	harReq, err = har.NewRequest(http.MethodPost, "http://example.com/login", []byte("a=1&b=2"), http.Header{"Content-Type": []string{"application/x-www-form-urlencoded"}}, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, []har.Param{{Name: "a", Value: "1"}, {Name: "b", Value: "2"}}, harReq.PostData.Params)
+}
+
+func writePart(t *testing.T, mw *multipart.Writer, name, fileName, ct string, content []byte) {
+	h := textproto.MIMEHeader{}
+	h.Set("Content-Disposition", `form-data; name="`+name+`"; filename="`+fileName+`"`)
+	h.Set("Content-Type", ct)
+	w, err := mw.CreatePart(h)
+	require.NoError(t, err)
+	_, err = w.Write(content)
+	require.NoError(t, err)
+}
@@ -0,0 +1,415 @@
+package har
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Issue is a violation of the HAR 1.2 specification.
+type Issue struct {
+	Path     string   `json:"path" yaml:"path" mapstructure:"path"` // e.g. log.entries[2].response.content.size
+	Severity Severity `json:"severity" yaml:"severity" mapstructure:"severity"`
+	Message  string   `json:"message" yaml:"message" mapstructure:"message"`
+	Fixed    bool     `json:"fixed,omitempty" yaml:"fixed,omitempty" mapstructure:"fixed,omitempty"` // the issue has been fixed in place (see WithAutoFix).
+}
+
+func (i Issue) String() string {
+	s := fmt.Sprintf("%s: %s: %s", i.Severity, i.Path, i.Message)
+	if i.Fixed {
+		s += " (fixed)"
+	}
+	return s
+}
+
+// TimeTolerance is the difference in milliseconds allowed between the time of an entry and the sum of its timings.
+const TimeTolerance = 0.001
+
+const defaultHTTPVersion = "HTTP/1.1"
+
+type validateOpts struct {
+	autoFix bool
+}
+
+type ValidateOption func(o *validateOpts)
+
+// WithAutoFix fixes in place the issues having an obvious remedy: missing mandatory objects and arrays, out of range
+// sizes and timings, time not matching the timings, sizes not matching the content, non ISO 8601 dates that can be parsed.
+func WithAutoFix(b bool) ValidateOption {
+	return func(o *validateOpts) {
+		o.autoFix = b
+	}
+}
+
+// Validate checks the document against the HAR 1.2 specification: required fields, date formats, time equal to the sum of
+// the available timings, consistency of body and headers sizes.
+func (h *HAR) Validate(opts ...ValidateOption) []Issue {
+	o := validateOpts{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	v := &validator{fix: o.autoFix}
+	if h.Log == nil {
+		v.add("log", SeverityError, "log is missing", false)
+		return v.issues
+	}
+
+	v.validateLog(h.Log)
+	return v.issues
+}
+
+type validator struct {
+	fix    bool
+	issues []Issue
+}
+
+// add records the issue, fixable tells if the caller fixes it when auto fix is enabled.
+func (v *validator) add(path string, severity Severity, msg string, fixable bool) bool {
+	fixed := fixable && v.fix
+	v.issues = append(v.issues, Issue{Path: path, Severity: severity, Message: msg, Fixed: fixed})
+	return fixed
+}
+
+func (v *validator) validateLog(l *Log) {
+	switch l.Version {
+	case "":
+		if v.add("log.version", SeverityWarning, "version is missing, 1.1 is assumed", true) {
+			l.Version = Version11
+		}
+	case Version11, Version12:
+	default:
+		v.add("log.version", SeverityWarning, fmt.Sprintf("unknown version %s", l.Version), false)
+	}
+
+	if l.Creator == nil {
+		if v.add("log.creator", SeverityError, "creator is missing", true) {
+			l.Creator = &Creator{Name: "tpm-har", Version: "1.0"}
+		}
+	} else {
+		v.validateCreator("log.creator", l.Creator)
+	}
+
+	if l.Browser != nil {
+		v.validateCreator("log.browser", l.Browser)
+	}
+
+	pages := map[string]bool{}
+	for i, p := range l.Pages {
+		path := fmt.Sprintf("log.pages[%d]", i)
+		if p == nil {
+			v.add(path, SeverityError, "page is null", false)
+			continue
+		}
+
+		pages[p.ID] = true
+		v.validatePage(path, p)
+	}
+
+	if l.Entries == nil {
+		if v.add("log.entries", SeverityError, "entries is missing", true) {
+			l.Entries = []*Entry{}
+		}
+	}
+
+	for i, e := range l.Entries {
+		path := fmt.Sprintf("log.entries[%d]", i)
+		if e == nil {
+			v.add(path, SeverityError, "entry is null", false)
+			continue
+		}
+
+		v.validateEntry(path, e, pages)
+	}
+}
+
+func (v *validator) validateCreator(path string, c *Creator) {
+	if c.Name == "" {
+		v.add(path+".name", SeverityError, "name is missing", false)
+	}
+
+	if c.Version == "" {
+		v.add(path+".version", SeverityError, "version is missing", false)
+	}
+}
+
+func (v *validator) validatePage(path string, p *Page) {
+	if p.ID == "" {
+		v.add(path+".id", SeverityError, "id is missing", false)
+	}
+
+	v.validateDateTime(path+".startedDateTime", &p.StartedDateTime, true)
+
+	if p.PageTimings == nil {
+		if v.add(path+".pageTimings", SeverityError, "pageTimings is missing", true) {
+			p.PageTimings = &PageTimings{OnContentLoad: -1, OnLoad: -1}
+		}
+	}
+}
+
+// validateDateTime checks the ISO 8601 format of the date. Dates in http format are fixed.
+func (v *validator) validateDateTime(path string, dt *string, required bool) {
+	if *dt == "" {
+		if required {
+			v.add(path, SeverityError, "date is missing", false)
+		}
+		return
+	}
+
+	if _, err := time.Parse(time.RFC3339Nano, *dt); err == nil {
+		return
+	}
+
+	t, err := http.ParseTime(*dt)
+	if v.add(path, SeverityError, fmt.Sprintf("date %q is not in ISO 8601 format", *dt), err == nil) {
+		*dt = t.Format(time.RFC3339Nano)
+	}
+}
+
+func (v *validator) validateEntry(path string, e *Entry, pages map[string]bool) {
+	if e.Pageref != "" && !pages[e.Pageref] {
+		v.add(path+".pageref", SeverityWarning, fmt.Sprintf("page %s not found", e.Pageref), false)
+	}
+
+	if e.StartedDateTime == "" && !e.StartDateTimeTm.IsZero() {
+		if v.add(path+".startedDateTime", SeverityError, "date is missing", true) {
+			e.StartedDateTime = e.StartDateTimeTm.Format(time.RFC3339Nano)
+		}
+	} else {
+		v.validateDateTime(path+".startedDateTime", &e.StartedDateTime, true)
+	}
+
+	if e.Request == nil {
+		v.add(path+".request", SeverityError, "request is missing", false)
+	} else {
+		v.validateRequest(path+".request", e.Request)
+	}
+
+	if e.Response == nil {
+		v.add(path+".response", SeverityError, "response is missing", false)
+	} else {
+		v.validateResponse(path+".response", e.Response)
+	}
+
+	if e.Cache == nil {
+		if v.add(path+".cache", SeverityError, "cache is missing", true) {
+			e.Cache = &Cache{}
+		}
+	}
+
+	if e.Time < 0 {
+		if v.add(path+".time", SeverityError, fmt.Sprintf("negative time %v", e.Time), e.Timings != nil) {
+			e.Time = e.Timings.Total()
+		}
+	}
+
+	if e.Timings == nil {
+		if v.add(path+".timings", SeverityError, "timings is missing", true) {
+			e.Timings = &Timings{Blocked: -1, DNS: -1, Connect: -1, Ssl: -1, Wait: math.Max(e.Time, 0)}
+		}
+		return
+	}
+
+	v.validateTimings(path+".timings", e.Timings)
+	if total := e.Timings.Total(); math.Abs(total-e.Time) > TimeTolerance {
+		if v.add(path+".time", SeverityError, fmt.Sprintf("time %v doesn't match the sum of the timings %v", e.Time, total), true) {
+			e.Time = total
+		}
+	}
+}
+
+// Total returns the sum of the available timings, ssl excluded since already part of connect.
+func (t *Timings) Total() float64 {
+	total := 0.0
+	for _, d := range []float64{t.Blocked, t.DNS, t.Connect, t.Send, t.Wait, t.Receive} {
+		if d > 0 {
+			total += d
+		}
+	}
+	return total
+}
+
+func (v *validator) validateTimings(path string, t *Timings) {
+	for _, f := range []struct {
+		name string
+		d    *float64
+	}{{"send", &t.Send}, {"wait", &t.Wait}, {"receive", &t.Receive}} {
+		if *f.d < 0 {
+			if v.add(path+"."+f.name, SeverityError, fmt.Sprintf("negative timing %v", *f.d), true) {
+				*f.d = 0
+			}
+		}
+	}
+
+	for _, f := range []struct {
+		name string
+		d    *float64
+	}{{"blocked", &t.Blocked}, {"dns", &t.DNS}, {"connect", &t.Connect}, {"ssl", &t.Ssl}} {
+		if *f.d < -1 {
+			if v.add(path+"."+f.name, SeverityError, fmt.Sprintf("timing %v lower than -1", *f.d), true) {
+				*f.d = -1
+			}
+		}
+	}
+
+	if t.Ssl > 0 && t.Connect >= 0 && t.Ssl > t.Connect {
+		v.add(path+".ssl", SeverityWarning, fmt.Sprintf("ssl %v should be included in connect %v", t.Ssl, t.Connect), false)
+	}
+}
+
+func (v *validator) validateRequest(path string, r *Request) {
+	if r.Method == "" {
+		v.add(path+".method", SeverityError, "method is missing", false)
+	}
+
+	if u, err := url.Parse(r.URL); err != nil || !u.IsAbs() || u.Host == "" {
+		v.add(path+".url", SeverityError, fmt.Sprintf("url %q is not absolute", r.URL), false)
+	}
+
+	if r.HTTPVersion == "" {
+		if v.add(path+".httpVersion", SeverityError, "httpVersion is missing", true) {
+			r.HTTPVersion = defaultHTTPVersion
+		}
+	}
+
+	v.validateCookies(path+".cookies", &r.Cookies)
+	v.validateNameValuePairs(path+".headers", &r.Headers)
+	v.validateNameValuePairs(path+".queryString", &r.QueryString)
+	v.validateHeadersSize(path+".headersSize", &r.HeadersSize)
+
+	if r.BodySize < -1 {
+		if v.add(path+".bodySize", SeverityError, fmt.Sprintf("size %d lower than -1", r.BodySize), true) {
+			r.BodySize = -1
+		}
+	}
+
+	if r.PostData == nil {
+		if r.BodySize > 0 {
+			v.add(path+".bodySize", SeverityWarning, fmt.Sprintf("size %d without postData", r.BodySize), false)
+		}
+		return
+	}
+
+	if r.PostData.MimeType == "" {
+		v.add(path+".postData.mimeType", SeverityError, "mimeType is missing", false)
+	}
+
+	if n := int64(len(r.PostData.Body())); n > 0 && r.BodySize != n && r.BodySize != -1 {
+		if v.add(path+".bodySize", SeverityWarning, fmt.Sprintf("size %d doesn't match the postData length %d", r.BodySize, n), true) {
+			r.BodySize = n
+		}
+	}
+}
+
+func (v *validator) validateResponse(path string, r *Response) {
+	if r.Status < 0 || r.Status > 999 {
+		v.add(path+".status", SeverityError, fmt.Sprintf("invalid status %d", r.Status), false)
+	}
+
+	if r.StatusText == "" && http.StatusText(r.Status) != "" {
+		if v.add(path+".statusText", SeverityWarning, "statusText is missing", true) {
+			r.StatusText = http.StatusText(r.Status)
+		}
+	}
+
+	if r.HTTPVersion == "" {
+		if v.add(path+".httpVersion", SeverityError, "httpVersion is missing", true) {
+			r.HTTPVersion = defaultHTTPVersion
+		}
+	}
+
+	v.validateCookies(path+".cookies", &r.Cookies)
+	v.validateNameValuePairs(path+".headers", &r.Headers)
+
+	hs := int64(r.HeadersSize)
+	v.validateHeadersSize(path+".headersSize", &hs)
+	r.HeadersSize = int(hs)
+
+	if r.BodySize < -1 {
+		if v.add(path+".bodySize", SeverityError, fmt.Sprintf("size %d lower than -1", r.BodySize), true) {
+			r.BodySize = -1
+		}
+	}
+
+	if r.Content == nil {
+		if v.add(path+".content", SeverityError, "content is missing", true) {
+			r.Content = &Content{MimeType: r.Headers.GetFirst("Content-Type").Value}
+		}
+		return
+	}
+
+	v.validateContent(path+".content", r.Content)
+
+	// without compression the content size and the body size are the same.
+	c := r.Content
+	if r.BodySize >= 0 && c.Size >= 0 && c.Compression == 0 && r.BodySize != c.Size && r.Status != http.StatusNotModified && r.Headers.GetFirst("Content-Encoding").Value == "" {
+		if v.add(path+".bodySize", SeverityWarning, fmt.Sprintf("size %d doesn't match the content size %d", r.BodySize, c.Size), true) {
+			r.BodySize = c.Size
+		}
+	}
+}
+
+func (v *validator) validateContent(path string, c *Content) {
+	if c.MimeType == "" {
+		v.add(path+".mimeType", SeverityWarning, "mimeType is missing", false)
+	}
+
+	n := int64(len(c.Body()))
+	switch {
+	case c.Size < 0:
+		if v.add(path+".size", SeverityError, fmt.Sprintf("negative size %d", c.Size), true) {
+			c.Size = n
+		}
+	case n > 0 && c.Size != n:
+		if v.add(path+".size", SeverityWarning, fmt.Sprintf("size %d doesn't match the text length %d", c.Size, n), true) {
+			c.Size = n
+		}
+	}
+}
+
+func (v *validator) validateHeadersSize(path string, n *int64) {
+	if *n < -1 {
+		if v.add(path, SeverityError, fmt.Sprintf("size %d lower than -1", *n), true) {
+			*n = -1
+		}
+	}
+}
+
+func (v *validator) validateNameValuePairs(path string, nvs *NameValuePairs) {
+	if *nvs == nil {
+		if v.add(path, SeverityError, "array is missing", true) {
+			*nvs = NameValuePairs{}
+		}
+	}
+
+	for i, nv := range *nvs {
+		if nv.Name == "" {
+			v.add(fmt.Sprintf("%s[%d].name", path, i), SeverityError, "name is missing", false)
+		}
+	}
+}
+
+func (v *validator) validateCookies(path string, cookies *[]Cookie) {
+	if *cookies == nil {
+		if v.add(path, SeverityError, "array is missing", true) {
+			*cookies = []Cookie{}
+		}
+	}
+
+	for i := range *cookies {
+		c := &(*cookies)[i]
+		if c.Name == "" {
+			v.add(fmt.Sprintf("%s[%d].name", path, i), SeverityError, "name is missing", false)
+		}
+		v.validateDateTime(fmt.Sprintf("%s[%d].expires", path, i), &c.Expires, false)
+	}
+}
@@ -0,0 +1,68 @@
+package har_test
+
+import (
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/har"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	req, err := har.NewRequest(http.MethodPost, "http://localhost:3004/api/v1/orders", []byte(`{"canale":"APPP"}`), http.Header{"Content-Type": []string{"application/json"}}, nil, nil)
+	require.NoError(t, err)
+	req.HeadersSize = -5
+
+	resp := har.NewResponse(200, "OK", "application/json", []byte(`{"esito":"OK"}`), nil)
+	resp.BodySize = 100
+	resp.Cookies = []har.Cookie{{Name: "session", Value: "abc", Expires: "Wed, 21 Oct 2015 07:28:00 GMT"}}
+
+	h := har.NewHAR(
+		har.WithEntry(&har.Entry{
+			StartedDateTime: "2023-02-12T20:07:02.147874+01:00",
+			Time:            10,
+			Request:         req,
+			Response:        resp,
+			Timings:         &har.Timings{Blocked: -1, DNS: -1, Connect: 2, Ssl: 1, Send: 1, Wait: 3, Receive: -1},
+		}),
+		har.WithEntry(&har.Entry{StartedDateTime: "12/02/2023", Request: &har.Request{Method: http.MethodGet, URL: "/relative"}}),
+	)
+
+	issues := h.Validate()
+	for _, i := range issues {
+		t.Log(i.String())
+	}
+
+	byPath := map[string]har.Issue{}
+	for _, i := range issues {
+		byPath[i.Path] = i
+		require.False(t, i.Fixed)
+	}
+
+	require.Contains(t, byPath, "log.entries[0].time")
+	require.Contains(t, byPath, "log.entries[0].cache")
+	require.Contains(t, byPath, "log.entries[0].request.headersSize")
+	require.Contains(t, byPath, "log.entries[0].response.bodySize")
+	require.Contains(t, byPath, "log.entries[0].response.cookies[0].expires")
+	require.Contains(t, byPath, "log.entries[0].timings.receive")
+	require.Equal(t, har.SeverityError, byPath["log.entries[1].startedDateTime"].Severity)
+	require.Contains(t, byPath, "log.entries[1].request.url")
+	require.Contains(t, byPath, "log.entries[1].response")
+	require.Contains(t, byPath, "log.entries[1].timings")
+
+	issues = h.Validate(har.WithAutoFix(true))
+	e := h.Log.Entries[0]
+	require.Equal(t, float64(6), e.Time)
+	require.Equal(t, float64(0), e.Timings.Receive)
+	require.Equal(t, int64(-1), e.Request.HeadersSize)
+	require.Equal(t, int64(14), e.Response.BodySize)
+	require.Equal(t, "2015-10-21T07:28:00Z", e.Response.Cookies[0].Expires)
+	require.NotNil(t, e.Cache)
+
+	// what's left can't be fixed.
+	var unfixed []string
+	for _, i := range h.Validate() {
+		unfixed = append(unfixed, i.Path)
+	}
+	require.ElementsMatch(t, []string{"log.entries[1].startedDateTime", "log.entries[1].request.url", "log.entries[1].response"}, unfixed)
+	require.NotEmpty(t, issues)
+}
@@ -0,0 +1,154 @@
+package har
+
+import (
+	"fmt"
+	"strconv"
+)
+
+const (
+	Version11 = "1.1"
+	Version12 = "1.2"
+
+	// DefaultVersion is the version of the documents created by the library and of the in-memory model.
+	DefaultVersion = Version12
+)
+
+// ConvertTo converts in place the document to the version. Upgrading to 1.2 fills the objects and the values made
+// mandatory by the specification; downgrading to 1.1 removes the fields introduced by 1.2 (ssl timing, server ip address,
+// connection and comments), keeping the ssl time in the connect one.
+func (h *HAR) ConvertTo(version string) error {
+	if h.Log == nil {
+		return nil
+	}
+
+	switch version {
+	case Version12:
+		h.Log.upgrade()
+	case Version11:
+		h.Log.downgrade()
+	default:
+		return fmt.Errorf("har: unsupported version %s", version)
+	}
+
+	return nil
+}
+
+// isOlderVersion tells if the version precedes 1.2, the empty version meaning 1.1.
+func isOlderVersion(v string) bool {
+	if v == "" {
+		return true
+	}
+
+	f, err := strconv.ParseFloat(v, 64)
+	return err == nil && f < 1.2
+}
+
+func (l *Log) upgrade() {
+	l.Version = Version12
+	for _, e := range l.Entries {
+		if e != nil {
+			e.upgrade()
+		}
+	}
+}
+
+// upgrade fills the mandatory objects and sets the timings not applicable in 1.1 (ssl) to -1. References to unknown pages
+// are kept: Validate reports them.
+func (e *Entry) upgrade() {
+	if e.Cache == nil {
+		e.Cache = &Cache{}
+	}
+
+	if e.Timings == nil {
+		e.Timings = &Timings{Blocked: -1, DNS: -1, Connect: -1, Ssl: -1, Wait: e.Time}
+		return
+	}
+
+	if e.Timings.Ssl == 0 {
+		e.Timings.Ssl = -1
+	}
+}
+
+func (l *Log) downgrade() {
+	l.Version = Version11
+	l.Comment = ""
+	if l.Creator != nil {
+		l.Creator.Comment = ""
+	}
+	if l.Browser != nil {
+		l.Browser.Comment = ""
+	}
+
+	for _, p := range l.Pages {
+		if p != nil {
+			p.Comment = ""
+			if p.PageTimings != nil {
+				p.PageTimings.Comment = ""
+			}
+		}
+	}
+
+	for _, e := range l.Entries {
+		if e != nil {
+			e.downgrade()
+		}
+	}
+}
+
+func (e *Entry) downgrade() {
+	e.Comment = ""
+	e.ServerIPAddress = ""
+	e.Connection = ""
+
+	if t := e.Timings; t != nil {
+		if t.Ssl > 0 && t.Connect < t.Ssl {
+			t.Connect = t.Ssl
+		}
+		t.Ssl = 0
+		t.Comment = ""
+	}
+
+	if c := e.Cache; c != nil {
+		c.Comment = ""
+		if c.BeforeRequest != nil {
+			c.BeforeRequest.Comment = ""
+		}
+		if c.AfterRequest != nil {
+			c.AfterRequest.Comment = ""
+		}
+	}
+
+	if r := e.Request; r != nil {
+		r.Comment = ""
+		clearCookieComments(r.Cookies)
+		clearPairComments(r.Headers)
+		clearPairComments(r.QueryString)
+		if r.PostData != nil {
+			r.PostData.Comment = ""
+			for i := range r.PostData.Params {
+				r.PostData.Params[i].Comment = ""
+			}
+		}
+	}
+
+	if r := e.Response; r != nil {
+		r.Comment = ""
+		clearCookieComments(r.Cookies)
+		clearPairComments(r.Headers)
+		if r.Content != nil {
+			r.Content.Comment = ""
+		}
+	}
+}
+
+func clearPairComments(nvs NameValuePairs) {
+	for i := range nvs {
+		nvs[i].Comment = ""
+	}
+}
+
+func clearCookieComments(cookies []Cookie) {
+	for i := range cookies {
+		cookies[i].Comment = ""
+	}
+}
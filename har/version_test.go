@@ -0,0 +1,75 @@
+package har_test
+
+import (
+	"bytes"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/har"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func TestVersion(t *testing.T) {
+	require.Equal(t, har.Version12, har.NewHAR().Log.Version)
+	require.Equal(t, har.Version11, har.NewHAR(har.WithVersion(har.Version11)).Log.Version)
+
+	src := `{"log":{"version":"1.1","creator":{"name":"test","version":"1.0"},"entries":[
+		{"pageref":"page_1","startedDateTime":"2023-02-12T20:07:02.147874+01:00","time":5,"timings":{"connect":2,"send":1,"wait":2,"receive":0}},
+		{"startedDateTime":"2023-02-12T20:07:02.149311+01:00","time":3}
+	]}}`
+
+	h, err := har.ReadHAR(strings.NewReader(src))
+	require.NoError(t, err)
+	require.Equal(t, har.Version12, h.Log.Version)
+	require.Equal(t, "page_1", h.Log.Entries[0].Pageref, "references to unknown pages are kept")
+	require.Equal(t, float64(-1), h.Log.Entries[0].Timings.Ssl)
+	require.Equal(t, float64(3), h.Log.Entries[1].Timings.Wait)
+	require.NotNil(t, h.Log.Entries[1].Cache)
+	pagerefIssues := 0
+	for _, issue := range h.Validate() {
+		require.NotContains(t, issue.Path, "time", issue.String())
+		if strings.HasSuffix(issue.Path, ".pageref") {
+			pagerefIssues++
+		}
+	}
+	require.Equal(t, 1, pagerefIssues, "unknown page references are reported by Validate")
+
+	dec := har.NewDecoder(strings.NewReader(src))
+	header, err := dec.Header()
+	require.NoError(t, err)
+	require.Equal(t, har.Version12, header.Version)
+	e, err := dec.Next()
+	require.NoError(t, err)
+	require.Equal(t, float64(-1), e.Timings.Ssl)
+
+	e = h.Log.Entries[0]
+	e.Comment = "a comment"
+	e.ServerIPAddress = "10.0.0.1"
+	e.Timings.Ssl = 3
+	require.NoError(t, h.ConvertTo(har.Version11))
+	require.Equal(t, har.Version11, h.Log.Version)
+	require.Empty(t, e.Comment)
+	require.Empty(t, e.ServerIPAddress)
+	require.Equal(t, float64(3), e.Timings.Connect, "the ssl time is part of connect")
+
+	var buf bytes.Buffer
+	require.NoError(t, h.Write(&buf, false))
+	require.NotContains(t, buf.String(), `"ssl"`)
+
+	require.Error(t, h.ConvertTo("2.0"))
+}
+
+func TestVersionPagesAfterEntries(t *testing.T) {
+	src := `{"log":{"version":"1.1","creator":{"name":"test","version":"1.0"},"entries":[
+		{"pageref":"p1","startedDateTime":"2023-02-12T20:07:02.147874+01:00","time":5}
+	],"pages":[{"id":"p1","startedDateTime":"2023-02-12T20:07:02.100000+01:00","title":"home","pageTimings":{}}]}}`
+
+	h, err := har.ReadHAR(strings.NewReader(src))
+	require.NoError(t, err)
+	require.Equal(t, "p1", h.Log.Entries[0].Pageref)
+
+	dec := har.NewDecoder(strings.NewReader(src))
+	e, err := dec.Next()
+	require.NoError(t, err)
+	require.Equal(t, "p1", e.Pageref)
+	require.NotNil(t, e.Cache, "the entry is upgraded")
+}
@@ -0,0 +1,99 @@
+package harmock
+
+import (
+	"encoding/json"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/har"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Mismatch is the body of the 501 response served when no recorded entry matches the request.
+type Mismatch struct {
+	Error      string      `json:"error"`
+	Method     string      `json:"method"`
+	URL        string      `json:"url"`
+	Candidates []Candidate `json:"candidates,omitempty"`
+}
+
+// Candidate is a recorded entry close to the unmatched request. Lower distances are closer.
+type Candidate struct {
+	Method   string `json:"method"`
+	URL      string `json:"url"`
+	Status   int    `json:"status"`
+	Distance int    `json:"distance"`
+}
+
+func (h *Handler) writeMismatch(w http.ResponseWriter, r *http.Request) {
+	m := Mismatch{
+		Error:      "no recorded entry matches the request",
+		Method:     r.Method,
+		URL:        r.URL.String(),
+		Candidates: h.nearest(r),
+	}
+
+	b, _ := json.MarshalIndent(m, "", "  ")
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusNotImplemented)
+	_, _ = w.Write(b)
+}
+
+func (h *Handler) nearest(r *http.Request) []Candidate {
+	var cands []Candidate
+	for _, e := range h.entries {
+		cands = append(cands, Candidate{
+			Method:   e.Request.Method,
+			URL:      e.Request.URL,
+			Status:   e.Response.Status,
+			Distance: distance(e, r),
+		})
+	}
+
+	sort.SliceStable(cands, func(i, j int) bool {
+		return cands[i].Distance < cands[j].Distance
+	})
+
+	if len(cands) > h.maxCandidates {
+		cands = cands[:h.maxCandidates]
+	}
+	return cands
+}
+
+// distance weighs a method mismatch as a whole path, then adds the edit distance of paths and queries.
+func distance(e *har.Entry, r *http.Request) int {
+	u, err := url.Parse(e.Request.URL)
+	if err != nil {
+		return int(^uint(0) >> 1)
+	}
+
+	d := levenshtein(normalizePath(u.Path), normalizePath(r.URL.Path))
+	d += levenshtein(canonicalQuery(entryQuery(e.Request, u)), canonicalQuery(r.URL.Query()))
+	if !strings.EqualFold(e.Request.Method, r.Method) {
+		d += len(r.URL.Path) + 1
+	}
+	return d
+}
+
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
@@ -0,0 +1,307 @@
+package harmock
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/har"
+	"github.com/rs/zerolog/log"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Strategy tells which response to serve when several recorded entries match the same request.
+type Strategy int
+
+const (
+	// Sequential serves the matching entries in recording order, the last one is repeated when exhausted.
+	Sequential Strategy = iota
+	// First always serves the first matching entry.
+	First
+	// RoundRobin cycles over the matching entries.
+	RoundRobin
+)
+
+const (
+	DefaultMaxCandidates = 3
+)
+
+type Handler struct {
+	mu            sync.Mutex
+	entries       []*har.Entry
+	strategy      Strategy
+	matchQuery    bool
+	matchBody     bool
+	maxCandidates int
+	served        map[string]int
+}
+
+type Option func(h *Handler)
+
+func WithStrategy(s Strategy) Option {
+	return func(h *Handler) {
+		h.strategy = s
+	}
+}
+
+// WithQueryMatching requires the query string to match. Enabled by default.
+func WithQueryMatching(b bool) Option {
+	return func(h *Handler) {
+		h.matchQuery = b
+	}
+}
+
+// WithBodyMatching requires the request body to match. Json bodies are compared after compaction.
+func WithBodyMatching(b bool) Option {
+	return func(h *Handler) {
+		h.matchBody = b
+	}
+}
+
+// WithMaxCandidates sets the number of nearest entries listed when a request doesn't match.
+func WithMaxCandidates(n int) Option {
+	return func(h *Handler) {
+		h.maxCandidates = n
+	}
+}
+
+// NewHandler creates an http.Handler answering with the responses recorded in the har.
+func NewHandler(h *har.HAR, opts ...Option) (*Handler, error) {
+	if h == nil || h.Log == nil {
+		return nil, fmt.Errorf("harmock: no har log provided")
+	}
+
+	hnd := &Handler{strategy: Sequential, matchQuery: true, maxCandidates: DefaultMaxCandidates, served: map[string]int{}}
+	for _, o := range opts {
+		o(hnd)
+	}
+
+	for _, e := range h.Log.Entries {
+		if e.Request != nil && e.Response != nil {
+			hnd.entries = append(hnd.entries, e)
+		}
+	}
+
+	return hnd, nil
+}
+
+// LoadHandler creates the handler from the named har file.
+func LoadHandler(fn string, opts ...Option) (*Handler, error) {
+	h, err := har.LoadFile(fn)
+	if err != nil {
+		return nil, err
+	}
+	return NewHandler(h, opts...)
+}
+
+// NewServer starts an httptest.Server serving the har. The caller has to Close it.
+func NewServer(h *har.HAR, opts ...Option) (*httptest.Server, error) {
+	hnd, err := NewHandler(h, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return httptest.NewServer(hnd), nil
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	const semLogContext = "har-mock::serve"
+
+	var body []byte
+	if r.Body != nil {
+		var err error
+		body, err = io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	e := h.match(r, body)
+	if e == nil {
+		log.Warn().Str("method", r.Method).Str("url", r.URL.String()).Msg(semLogContext + " no matching entry")
+		h.writeMismatch(w, r)
+		return
+	}
+
+	writeResponse(w, e.Response)
+}
+
+// Match returns the entry that would be served for the request, without consuming it.
+func (h *Handler) Match(r *http.Request, body []byte) *har.Entry {
+	matches := h.matches(r, body)
+	if len(matches) == 0 {
+		return nil
+	}
+	return matches[0]
+}
+
+func (h *Handler) match(r *http.Request, body []byte) *har.Entry {
+	matches := h.matches(r, body)
+	if len(matches) == 0 {
+		return nil
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	k := h.requestKey(r, body)
+	n := h.served[k]
+	h.served[k] = n + 1
+
+	switch h.strategy {
+	case First:
+		return matches[0]
+	case RoundRobin:
+		return matches[n%len(matches)]
+	default:
+		if n >= len(matches) {
+			n = len(matches) - 1
+		}
+		return matches[n]
+	}
+}
+
+// Reset clears the counters used by the Sequential and RoundRobin strategies.
+func (h *Handler) Reset() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.served = map[string]int{}
+}
+
+func (h *Handler) matches(r *http.Request, body []byte) []*har.Entry {
+	var res []*har.Entry
+	for _, e := range h.entries {
+		if h.entryMatches(e, r, body) {
+			res = append(res, e)
+		}
+	}
+	return res
+}
+
+func (h *Handler) entryMatches(e *har.Entry, r *http.Request, body []byte) bool {
+	if !strings.EqualFold(e.Request.Method, r.Method) {
+		return false
+	}
+
+	u, err := url.Parse(e.Request.URL)
+	if err != nil || normalizePath(u.Path) != normalizePath(r.URL.Path) {
+		return false
+	}
+
+	if h.matchQuery && canonicalQuery(entryQuery(e.Request, u)) != canonicalQuery(r.URL.Query()) {
+		return false
+	}
+
+	if h.matchBody {
+		var recorded []byte
+		if e.Request.PostData != nil {
+			recorded = e.Request.PostData.Body()
+		}
+		if !bytes.Equal(normalizeBody(recorded), normalizeBody(body)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (h *Handler) requestKey(r *http.Request, body []byte) string {
+	k := r.Method + " " + normalizePath(r.URL.Path)
+	if h.matchQuery {
+		k += "?" + canonicalQuery(r.URL.Query())
+	}
+	if h.matchBody {
+		k += " " + string(normalizeBody(body))
+	}
+	return k
+}
+
+func entryQuery(req *har.Request, u *url.URL) url.Values {
+	if u.RawQuery != "" {
+		return u.Query()
+	}
+
+	q := url.Values{}
+	for _, nv := range req.QueryString {
+		q.Add(nv.Name, nv.Value)
+	}
+	return q
+}
+
+func canonicalQuery(q url.Values) string {
+	for _, vs := range q {
+		sort.Strings(vs)
+	}
+	return q.Encode()
+}
+
+func normalizePath(p string) string {
+	if p == "" {
+		return "/"
+	}
+	if len(p) > 1 {
+		p = strings.TrimSuffix(p, "/")
+	}
+	return p
+}
+
+func normalizeBody(b []byte) []byte {
+	b = bytes.TrimSpace(b)
+	if len(b) > 0 && (b[0] == '{' || b[0] == '[') {
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, b); err == nil {
+			return buf.Bytes()
+		}
+	}
+	return b
+}
+
+// skippedResponseHeaders are computed by the server when writing the body.
+var skippedResponseHeaders = map[string]struct{}{
+	"content-length":    {},
+	"transfer-encoding": {},
+	"connection":        {},
+}
+
+func writeResponse(w http.ResponseWriter, resp *har.Response) {
+	decoded := resp.HasDecodedContent()
+	for _, nv := range resp.Headers {
+		if _, ok := skippedResponseHeaders[strings.ToLower(nv.Name)]; ok {
+			continue
+		}
+
+		// the content is served as recorded, already decoded.
+		if decoded && strings.EqualFold(nv.Name, "Content-Encoding") {
+			continue
+		}
+		w.Header().Add(nv.Name, nv.Value)
+	}
+
+	var body []byte
+	if resp.Content != nil {
+		body = resp.Content.Body()
+		if w.Header().Get("Content-Type") == "" && resp.Content.MimeType != "" {
+			w.Header().Set("Content-Type", resp.Content.MimeType)
+		}
+	}
+
+	// recorded cookies are used only if the Set-Cookie headers didn't make it into the har.
+	if w.Header().Get("Set-Cookie") == "" {
+		for _, c := range resp.Cookies {
+			http.SetCookie(w, &http.Cookie{Name: c.Name, Value: c.Value, Path: c.Path, Domain: c.Domain, HttpOnly: c.HTTPOnly, Secure: c.Secure})
+		}
+	}
+
+	status := resp.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+}
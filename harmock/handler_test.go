@@ -0,0 +1,116 @@
+package harmock_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/har"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/harmock"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func mockHAR() *har.HAR {
+	entry := func(method, u string, body string, status int, respBody string) *har.Entry {
+		req := &har.Request{Method: method, URL: u}
+		if body != "" {
+			req.PostData = &har.PostData{MimeType: "application/json", Text: body}
+		}
+		return &har.Entry{Request: req, Response: har.NewResponse(status, http.StatusText(status), "application/json", []byte(respBody), nil)}
+	}
+
+	return har.NewHAR(
+		har.WithEntry(entry(http.MethodGet, "http://backend/api/v1/items/1?lang=it", "", 200, `{"id":1,"v":"first"}`)),
+		har.WithEntry(entry(http.MethodGet, "http://backend/api/v1/items/1?lang=it", "", 200, `{"id":1,"v":"second"}`)),
+		har.WithEntry(entry(http.MethodPost, "http://backend/api/v1/items", `{"id": 2}`, 201, `{"id":2}`)),
+		har.WithEntry(entry(http.MethodPost, "http://backend/api/v1/items", `{"id": 3}`, 409, `{"error":"conflict"}`)),
+	)
+}
+
+func get(t *testing.T, u string) (int, string) {
+	resp, err := http.Get(u)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, string(b)
+}
+
+func TestStrategies(t *testing.T) {
+	for _, tc := range []struct {
+		strategy harmock.Strategy
+		expected []string
+	}{
+		{harmock.Sequential, []string{"first", "second", "second"}},
+		{harmock.First, []string{"first", "first", "first"}},
+		{harmock.RoundRobin, []string{"first", "second", "first"}},
+	} {
+		srv, err := harmock.NewServer(mockHAR(), harmock.WithStrategy(tc.strategy))
+		require.NoError(t, err)
+
+		for _, exp := range tc.expected {
+			sc, body := get(t, srv.URL+"/api/v1/items/1?lang=it")
+			require.Equal(t, http.StatusOK, sc)
+			require.Contains(t, body, exp)
+		}
+		srv.Close()
+	}
+}
+
+func TestBodyMatching(t *testing.T) {
+	srv, err := harmock.NewServer(mockHAR(), harmock.WithBodyMatching(true))
+	require.NoError(t, err)
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+"/api/v1/items", "application/json", strings.NewReader(`{"id":3}`))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusConflict, resp.StatusCode)
+}
+
+func TestMismatch(t *testing.T) {
+	srv, err := harmock.NewServer(mockHAR())
+	require.NoError(t, err)
+	defer srv.Close()
+
+	sc, body := get(t, srv.URL+"/api/v1/item/1?lang=it")
+	require.Equal(t, http.StatusNotImplemented, sc)
+	t.Log(body)
+
+	var m harmock.Mismatch
+	require.NoError(t, json.Unmarshal([]byte(body), &m))
+	require.Len(t, m.Candidates, harmock.DefaultMaxCandidates)
+	require.Equal(t, "http://backend/api/v1/items/1?lang=it", m.Candidates[0].URL)
+}
+
+func TestDecodedContent(t *testing.T) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, _ = zw.Write([]byte(`{"id":1,"v":"decoded"}`))
+	require.NoError(t, zw.Close())
+
+	httpResp := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(bytes.NewReader(buf.Bytes()))}
+	httpResp.Header.Set("Content-Type", "application/json")
+	httpResp.Header.Set("Content-Encoding", "gzip")
+	resp, err := har.NewResponseFromHttpResponse(httpResp, har.WithContentDecoding(true))
+	require.NoError(t, err)
+
+	srv, err := harmock.NewServer(har.NewHAR(har.WithEntry(&har.Entry{Request: &har.Request{Method: http.MethodGet, URL: "http://backend/api/v1/items/1"}, Response: resp})))
+	require.NoError(t, err)
+	defer srv.Close()
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/api/v1/items/1", nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept-Encoding", "gzip")
+	r, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer r.Body.Close()
+
+	b, err := io.ReadAll(r.Body)
+	require.NoError(t, err)
+	require.Empty(t, r.Header.Get("Content-Encoding"))
+	require.Equal(t, `{"id":1,"v":"decoded"}`, string(b))
+}
@@ -0,0 +1,235 @@
+package haropenapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Types of the OpenAPI 3 document. Only the subset produced by the generator and checked by the validator is modelled.
+//
+// See: https://spec.openapis.org/oas/v3.0.3
+
+const (
+	Version = "3.0.3"
+
+	FormatYAML = "yaml"
+	FormatJSON = "json"
+)
+
+type Document struct {
+	OpenAPI    string               `json:"openapi" yaml:"openapi" mapstructure:"openapi"`
+	Info       Info                 `json:"info" yaml:"info" mapstructure:"info"`
+	Servers    []Server             `json:"servers,omitempty" yaml:"servers,omitempty" mapstructure:"servers,omitempty"`
+	Paths      map[string]*PathItem `json:"paths" yaml:"paths" mapstructure:"paths"`
+	Components *Components          `json:"components,omitempty" yaml:"components,omitempty" mapstructure:"components,omitempty"`
+}
+
+type Info struct {
+	Title       string `json:"title" yaml:"title" mapstructure:"title"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty" mapstructure:"description,omitempty"`
+	Version     string `json:"version" yaml:"version" mapstructure:"version"`
+}
+
+type Server struct {
+	URL string `json:"url" yaml:"url" mapstructure:"url"`
+}
+
+type Components struct {
+	Schemas    map[string]*Schema    `json:"schemas,omitempty" yaml:"schemas,omitempty" mapstructure:"schemas,omitempty"`
+	Parameters map[string]*Parameter `json:"parameters,omitempty" yaml:"parameters,omitempty" mapstructure:"parameters,omitempty"`
+}
+
+type PathItem struct {
+	Parameters []*Parameter `json:"parameters,omitempty" yaml:"parameters,omitempty" mapstructure:"parameters,omitempty"`
+	Get        *Operation   `json:"get,omitempty" yaml:"get,omitempty" mapstructure:"get,omitempty"`
+	Put        *Operation   `json:"put,omitempty" yaml:"put,omitempty" mapstructure:"put,omitempty"`
+	Post       *Operation   `json:"post,omitempty" yaml:"post,omitempty" mapstructure:"post,omitempty"`
+	Delete     *Operation   `json:"delete,omitempty" yaml:"delete,omitempty" mapstructure:"delete,omitempty"`
+	Options    *Operation   `json:"options,omitempty" yaml:"options,omitempty" mapstructure:"options,omitempty"`
+	Head       *Operation   `json:"head,omitempty" yaml:"head,omitempty" mapstructure:"head,omitempty"`
+	Patch      *Operation   `json:"patch,omitempty" yaml:"patch,omitempty" mapstructure:"patch,omitempty"`
+	Trace      *Operation   `json:"trace,omitempty" yaml:"trace,omitempty" mapstructure:"trace,omitempty"`
+}
+
+// Operation returns the operation of the path item associated to the http method, nil if not documented.
+func (pi *PathItem) Operation(method string) *Operation {
+	if p := pi.operationRef(method); p != nil {
+		return *p
+	}
+	return nil
+}
+
+// SetOperation associates the operation to the http method. Methods not supported by OpenAPI are rejected.
+func (pi *PathItem) SetOperation(method string, op *Operation) error {
+	p := pi.operationRef(method)
+	if p == nil {
+		return fmt.Errorf("method %s not supported by openapi", method)
+	}
+
+	*p = op
+	return nil
+}
+
+func (pi *PathItem) operationRef(method string) **Operation {
+	switch strings.ToUpper(method) {
+	case http.MethodGet:
+		return &pi.Get
+	case http.MethodPut:
+		return &pi.Put
+	case http.MethodPost:
+		return &pi.Post
+	case http.MethodDelete:
+		return &pi.Delete
+	case http.MethodOptions:
+		return &pi.Options
+	case http.MethodHead:
+		return &pi.Head
+	case http.MethodPatch:
+		return &pi.Patch
+	case http.MethodTrace:
+		return &pi.Trace
+	}
+	return nil
+}
+
+type Operation struct {
+	OperationId string               `json:"operationId,omitempty" yaml:"operationId,omitempty" mapstructure:"operationId,omitempty"`
+	Summary     string               `json:"summary,omitempty" yaml:"summary,omitempty" mapstructure:"summary,omitempty"`
+	Parameters  []*Parameter         `json:"parameters,omitempty" yaml:"parameters,omitempty" mapstructure:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty" yaml:"requestBody,omitempty" mapstructure:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses" yaml:"responses" mapstructure:"responses"`
+}
+
+const (
+	InPath   = "path"
+	InQuery  = "query"
+	InHeader = "header"
+	InCookie = "cookie"
+)
+
+type Parameter struct {
+	Ref      string      `json:"$ref,omitempty" yaml:"$ref,omitempty" mapstructure:"$ref,omitempty"`
+	Name     string      `json:"name,omitempty" yaml:"name,omitempty" mapstructure:"name,omitempty"`
+	In       string      `json:"in,omitempty" yaml:"in,omitempty" mapstructure:"in,omitempty"`
+	Required bool        `json:"required,omitempty" yaml:"required,omitempty" mapstructure:"required,omitempty"`
+	Schema   *Schema     `json:"schema,omitempty" yaml:"schema,omitempty" mapstructure:"schema,omitempty"`
+	Example  interface{} `json:"example,omitempty" yaml:"example,omitempty" mapstructure:"example,omitempty"`
+}
+
+type RequestBody struct {
+	Required bool                  `json:"required,omitempty" yaml:"required,omitempty" mapstructure:"required,omitempty"`
+	Content  map[string]*MediaType `json:"content" yaml:"content" mapstructure:"content"`
+}
+
+type Response struct {
+	Description string                `json:"description" yaml:"description" mapstructure:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty" yaml:"content,omitempty" mapstructure:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema  *Schema     `json:"schema,omitempty" yaml:"schema,omitempty" mapstructure:"schema,omitempty"`
+	Example interface{} `json:"example,omitempty" yaml:"example,omitempty" mapstructure:"example,omitempty"`
+}
+
+const (
+	TypeObject  = "object"
+	TypeArray   = "array"
+	TypeString  = "string"
+	TypeNumber  = "number"
+	TypeInteger = "integer"
+	TypeBoolean = "boolean"
+)
+
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty" yaml:"$ref,omitempty" mapstructure:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty" yaml:"type,omitempty" mapstructure:"type,omitempty"`
+	Format               string             `json:"format,omitempty" yaml:"format,omitempty" mapstructure:"format,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty" yaml:"nullable,omitempty" mapstructure:"nullable,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty" yaml:"enum,omitempty" mapstructure:"enum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty" yaml:"properties,omitempty" mapstructure:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty" yaml:"required,omitempty" mapstructure:"required,omitempty"`
+	AdditionalProperties interface{}        `json:"additionalProperties,omitempty" yaml:"additionalProperties,omitempty" mapstructure:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty" yaml:"items,omitempty" mapstructure:"items,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty" yaml:"allOf,omitempty" mapstructure:"allOf,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty" yaml:"anyOf,omitempty" mapstructure:"anyOf,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty" yaml:"oneOf,omitempty" mapstructure:"oneOf,omitempty"`
+	Example              interface{}        `json:"example,omitempty" yaml:"example,omitempty" mapstructure:"example,omitempty"`
+
+	null bool // inferred from null samples only: nullable can't be set without a type.
+}
+
+// ReadDocument decodes an OpenAPI document, either json or yaml, from the reader.
+func ReadDocument(r io.Reader) (*Document, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc Document
+
+	// yaml is a superset of json.
+	if err = yaml.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+
+	if doc.Paths == nil {
+		doc.Paths = map[string]*PathItem{}
+	}
+
+	return &doc, nil
+}
+
+// LoadFile reads the OpenAPI document stored in the named file.
+func LoadFile(fn string) (*Document, error) {
+	f, err := os.Open(fn)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ReadDocument(f)
+}
+
+// Write encodes the document on the writer in the requested format (FormatYAML or FormatJSON).
+func (doc *Document) Write(w io.Writer, format string) error {
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(doc)
+	case FormatYAML, "":
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(doc); err != nil {
+			return err
+		}
+		return enc.Close()
+	}
+
+	return fmt.Errorf("unsupported format %s: wanted one of %s, %s", format, FormatYAML, FormatJSON)
+}
+
+// WriteFile stores the document in the named file, the format is taken from the extension (.json or yaml otherwise).
+func (doc *Document) WriteFile(fn string) error {
+	format := FormatYAML
+	if strings.EqualFold(filepath.Ext(fn), ".json") {
+		format = FormatJSON
+	}
+
+	f, err := os.Create(fn)
+	if err != nil {
+		return err
+	}
+
+	err = doc.Write(f, format)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,433 @@
+package haropenapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/har"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type generatorOpts struct {
+	title    string
+	version  string
+	examples bool
+}
+
+type Option func(o *generatorOpts)
+
+func WithTitle(t string) Option {
+	return func(o *generatorOpts) {
+		o.title = t
+	}
+}
+
+func WithVersion(v string) Option {
+	return func(o *generatorOpts) {
+		o.version = v
+	}
+}
+
+// WithExamples adds the first recorded value of parameters and bodies as example. Enabled by default.
+func WithExamples(b bool) Option {
+	return func(o *generatorOpts) {
+		o.examples = b
+	}
+}
+
+// Generate infers an OpenAPI document from the entries of the har. The entries are clustered by method and path template:
+// the segments matching the Request.PathParams of the entry become named parameters, the ones looking like identifiers
+// (see har.IsPathIdSegment) become id parameters. Query parameters and json bodies are described by schemas inferred
+// from all the samples of the operation.
+func Generate(h *har.HAR, opts ...Option) (*Document, error) {
+	o := generatorOpts{title: "har", version: "1.0.0", examples: true}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    Info{Title: o.title, Version: o.version},
+		Paths:   map[string]*PathItem{},
+	}
+
+	if h == nil || h.Log == nil {
+		return doc, nil
+	}
+
+	var ops []*operationSamples
+	opsByKey := map[string]*operationSamples{}
+	servers := map[string]bool{}
+	templates := pathTemplates{}
+	for _, e := range h.Log.Entries {
+		if e.Request == nil {
+			continue
+		}
+
+		u, err := url.Parse(e.Request.URL)
+		if err != nil {
+			return nil, err
+		}
+
+		if u.Host != "" && !servers[u.Scheme+"://"+u.Host] {
+			servers[u.Scheme+"://"+u.Host] = true
+			doc.Servers = append(doc.Servers, Server{URL: u.Scheme + "://" + u.Host})
+		}
+
+		templ, pathParams := templates.normalize(pathTemplate(u.Path, e.Request.PathParams))
+		method := strings.ToUpper(e.Request.Method)
+		if method == "" {
+			method = http.MethodGet
+		}
+
+		k := method + " " + templ
+		op, ok := opsByKey[k]
+		if !ok {
+			op = newOperationSamples(method, templ)
+			opsByKey[k] = op
+			ops = append(ops, op)
+		}
+
+		op.add(e, u, pathParams)
+	}
+
+	opIds := map[string]int{}
+	for _, op := range ops {
+		pi, ok := doc.Paths[op.template]
+		if !ok {
+			pi = &PathItem{}
+			doc.Paths[op.template] = pi
+		}
+
+		if err := pi.SetOperation(op.method, op.operation(&o, opIds)); err != nil {
+			return nil, err
+		}
+	}
+
+	return doc, nil
+}
+
+// pathTemplate returns the templated path and the values of the path parameters in order of appearance.
+func pathTemplate(p string, params []har.Param) (string, []har.Param) {
+	var values []har.Param
+	used := map[string]bool{}
+
+	segs := strings.Split(p, "/")
+	for i, s := range segs {
+		if s == "" {
+			continue
+		}
+
+		n := ""
+		for _, pp := range params {
+			if pp.Name != "" && pp.Value == s && !used[pp.Name] {
+				n = pp.Name
+				break
+			}
+		}
+
+		if n == "" && har.IsPathIdSegment(s) {
+			n = "id"
+			for i := 2; used[n]; i++ {
+				n = "id" + strconv.Itoa(i)
+			}
+		}
+
+		if n != "" {
+			used[n] = true
+			values = append(values, har.Param{Name: n, Value: s})
+			segs[i] = "{" + n + "}"
+		}
+	}
+
+	templ := strings.Join(segs, "/")
+	if templ == "" {
+		templ = "/"
+	}
+	return templ, values
+}
+
+var placeholderRegexp = regexp.MustCompile(`\{([^}]*)\}`)
+
+// pathTemplates maps the paths, placeholders aside, to the first template found so that the same path gets
+// the same parameter names whatever the path params declared by the entries.
+type pathTemplates map[string]string
+
+func (pt pathTemplates) normalize(templ string, values []har.Param) (string, []har.Param) {
+	k := placeholderRegexp.ReplaceAllString(templ, "{}")
+	first, ok := pt[k]
+	if !ok {
+		pt[k] = templ
+		return templ, values
+	}
+
+	for i, m := range placeholderRegexp.FindAllStringSubmatch(first, -1) {
+		values[i].Name = m[1]
+	}
+	return first, values
+}
+
+type paramSamples struct {
+	name    string
+	count   int
+	schema  *Schema
+	example interface{}
+}
+
+func (ps *paramSamples) add(v string, first bool) {
+	if first {
+		ps.count++
+	}
+	ps.schema = MergeSchemas(ps.schema, InferStringSchema(v))
+	if ps.schema.Type == "" {
+		// values of different kinds can always be read as strings.
+		ps.schema = &Schema{Type: TypeString}
+	}
+
+	if ps.example == nil {
+		ps.example = v
+	}
+}
+
+type bodySamples struct {
+	schema  *Schema
+	example interface{}
+}
+
+type responseSamples struct {
+	description string
+	content     map[string]*bodySamples
+}
+
+type operationSamples struct {
+	method     string
+	template   string
+	count      int
+	pathParams []*paramSamples
+	query      []*paramSamples
+	reqBodies  int
+	reqContent map[string]*bodySamples
+	responses  map[int]*responseSamples
+}
+
+func newOperationSamples(method, templ string) *operationSamples {
+	return &operationSamples{
+		method:     method,
+		template:   templ,
+		reqContent: map[string]*bodySamples{},
+		responses:  map[int]*responseSamples{},
+	}
+}
+
+func (op *operationSamples) add(e *har.Entry, u *url.URL, pathParams []har.Param) {
+	op.count++
+
+	for _, pp := range pathParams {
+		addParamSample(&op.pathParams, pp.Name, pp.Value, true)
+	}
+
+	query := u.Query()
+	if u.RawQuery == "" {
+		for _, nv := range e.Request.QueryString {
+			query.Add(nv.Name, nv.Value)
+		}
+	}
+
+	for n, vs := range query {
+		for i, v := range vs {
+			addParamSample(&op.query, n, v, i == 0)
+		}
+	}
+	sort.Slice(op.query, func(i, j int) bool { return op.query[i].name < op.query[j].name })
+
+	if po := e.Request.PostData; po != nil && (len(po.Body()) > 0 || len(po.Params) > 0) {
+		op.reqBodies++
+		addBodySample(op.reqContent, po.MimeType, po.Body(), po.Params)
+	}
+
+	if e.Response == nil || e.Response.Status == 0 {
+		return
+	}
+
+	rs, ok := op.responses[e.Response.Status]
+	if !ok {
+		rs = &responseSamples{description: e.Response.StatusText, content: map[string]*bodySamples{}}
+		if rs.description == "" {
+			rs.description = http.StatusText(e.Response.Status)
+		}
+		if rs.description == "" {
+			rs.description = "response"
+		}
+		op.responses[e.Response.Status] = rs
+	}
+
+	if c := e.Response.Content; c != nil {
+		if b := c.Body(); len(b) > 0 {
+			addBodySample(rs.content, c.MimeType, b, nil)
+		}
+	}
+}
+
+// addParamSample adds the value to the samples of the parameter. Repeated parameters are counted once per entry:
+// first tells if the value is the first one found in the entry.
+func addParamSample(params *[]*paramSamples, n, v string, first bool) {
+	for _, ps := range *params {
+		if ps.name == n {
+			ps.add(v, first)
+			return
+		}
+	}
+
+	ps := &paramSamples{name: n}
+	ps.add(v, first)
+	*params = append(*params, ps)
+}
+
+func addBodySample(content map[string]*bodySamples, mimeType string, body []byte, params []har.Param) {
+	mt, _, err := mime.ParseMediaType(mimeType)
+	if err != nil || mt == "" {
+		mt = "application/octet-stream"
+	}
+
+	bs, ok := content[mt]
+	if !ok {
+		bs = &bodySamples{}
+		content[mt] = bs
+	}
+
+	var s *Schema
+	var example interface{}
+	switch {
+	case isJsonMediaType(mt):
+		var v interface{}
+		if err := json.Unmarshal(body, &v); err == nil {
+			s, example = InferSchema(v), v
+		} else {
+			s, example = &Schema{Type: TypeString}, string(body)
+		}
+
+	case mt == "application/x-www-form-urlencoded" || mt == "multipart/form-data":
+		form := map[string]interface{}{}
+		if len(params) == 0 {
+			if q, err := url.ParseQuery(string(body)); err == nil {
+				for _, nv := range har.NameValuePairsFromValues(q) {
+					params = append(params, har.Param{Name: nv.Name, Value: nv.Value})
+				}
+			}
+		}
+
+		s = &Schema{Type: TypeObject, Properties: map[string]*Schema{}}
+		for _, p := range params {
+			if p.FileName != "" {
+				s.Properties[p.Name] = &Schema{Type: TypeString, Format: "binary"}
+				continue
+			}
+			s.Properties[p.Name] = InferStringSchema(p.Value)
+			form[p.Name] = p.Value
+			s.Required = append(s.Required, p.Name)
+		}
+		sort.Strings(s.Required)
+		example = form
+
+	case strings.HasPrefix(mt, "text/") || strings.Contains(mt, "xml"):
+		s, example = &Schema{Type: TypeString}, string(body)
+
+	default:
+		s = &Schema{Type: TypeString, Format: "binary"}
+	}
+
+	bs.schema = MergeSchemas(bs.schema, s)
+	if bs.example == nil {
+		bs.example = example
+	}
+}
+
+func isJsonMediaType(mt string) bool {
+	return mt == "application/json" || strings.HasSuffix(mt, "+json")
+}
+
+func (op *operationSamples) operation(o *generatorOpts, opIds map[string]int) *Operation {
+	res := &Operation{
+		OperationId: operationId(op.method, op.template, opIds),
+		Summary:     op.method + " " + op.template,
+		Responses:   map[string]*Response{},
+	}
+
+	for _, ps := range op.pathParams {
+		res.Parameters = append(res.Parameters, ps.parameter(InPath, true, o.examples))
+	}
+
+	for _, ps := range op.query {
+		res.Parameters = append(res.Parameters, ps.parameter(InQuery, ps.count == op.count, o.examples))
+	}
+
+	if len(op.reqContent) > 0 {
+		res.RequestBody = &RequestBody{Required: op.reqBodies == op.count, Content: mediaTypes(op.reqContent, o.examples)}
+	}
+
+	for status, rs := range op.responses {
+		r := &Response{Description: rs.description}
+		if len(rs.content) > 0 {
+			r.Content = mediaTypes(rs.content, o.examples)
+		}
+		res.Responses[strconv.Itoa(status)] = r
+	}
+
+	if len(res.Responses) == 0 {
+		res.Responses["default"] = &Response{Description: "response"}
+	}
+
+	return res
+}
+
+func (ps *paramSamples) parameter(in string, required bool, examples bool) *Parameter {
+	p := &Parameter{Name: ps.name, In: in, Required: required, Schema: ps.schema}
+	if examples {
+		p.Example = ps.example
+	}
+	return p
+}
+
+func mediaTypes(content map[string]*bodySamples, examples bool) map[string]*MediaType {
+	res := map[string]*MediaType{}
+	for mt, bs := range content {
+		m := &MediaType{Schema: bs.schema}
+		if examples {
+			m.Example = bs.example
+		}
+		res[mt] = m
+	}
+	return res
+}
+
+var nonWordRegexp = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// operationId builds a camel case identifier from the method and the path template, e.g. getApiV1ItemsById.
+func operationId(method, templ string, opIds map[string]int) string {
+	var sb strings.Builder
+	sb.WriteString(strings.ToLower(method))
+	for _, s := range strings.Split(templ, "/") {
+		if strings.HasPrefix(s, "{") {
+			sb.WriteString("By")
+			s = strings.Trim(s, "{}")
+		}
+
+		for _, w := range nonWordRegexp.Split(s, -1) {
+			if w != "" {
+				sb.WriteString(strings.ToUpper(w[:1]) + w[1:])
+			}
+		}
+	}
+
+	id := sb.String()
+	opIds[id]++
+	if n := opIds[id]; n > 1 {
+		id = fmt.Sprintf("%s%d", id, n)
+	}
+	return id
+}
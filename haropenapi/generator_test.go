@@ -0,0 +1,113 @@
+package haropenapi_test
+
+import (
+	"bytes"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/har"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/haropenapi"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+func testHAR(t *testing.T) *har.HAR {
+	var entries []har.BuilderOption
+	for i, body := range []string{`{"canale":"APPP","importo":10}`, `{"canale":"WEB","importo":10.5,"note":null}`} {
+		req, err := har.NewRequest(http.MethodPost, "http://localhost:3004/api/v1/orders?lang=it", []byte(body), http.Header{"Content-Type": []string{"application/json"}}, nil, nil)
+		require.NoError(t, err)
+		entries = append(entries, har.WithEntry(&har.Entry{
+			Request:  req,
+			Response: har.NewResponse(201, "Created", "application/json", []byte(`{"id":"`+[]string{"10724279", "10724280"}[i]+`"}`), nil),
+		}))
+	}
+
+	for _, p := range []string{"/api/v1/orders/10724279", "/api/v1/orders/3f9c2a50-8f0e-4f6e-9d1a-2b8f1c0e9a11"} {
+		req, err := har.NewRequest(http.MethodGet, "http://localhost:3004"+p, nil, http.Header{}, nil, nil)
+		require.NoError(t, err)
+		entries = append(entries, har.WithEntry(&har.Entry{
+			Request:  req,
+			Response: har.NewResponse(200, "OK", "application/json", []byte(`{"items":[{"sku":"a","qty":1}],"created":"2023-02-12T20:07:02+01:00"}`), nil),
+		}))
+	}
+
+	return har.NewHAR(entries...)
+}
+
+func TestGenerate(t *testing.T) {
+	doc, err := haropenapi.Generate(testHAR(t), haropenapi.WithTitle("orders"))
+	require.NoError(t, err)
+
+	require.Equal(t, []haropenapi.Server{{URL: "http://localhost:3004"}}, doc.Servers)
+	require.Len(t, doc.Paths, 2)
+
+	post := doc.Paths["/api/v1/orders"].Post
+	require.NotNil(t, post)
+	require.Equal(t, "postApiV1Orders", post.OperationId)
+	require.Len(t, post.Parameters, 1)
+	require.Equal(t, haropenapi.InQuery, post.Parameters[0].In)
+	require.True(t, post.Parameters[0].Required)
+
+	s := post.RequestBody.Content["application/json"].Schema
+	require.Equal(t, haropenapi.TypeObject, s.Type)
+	require.Equal(t, []string{"canale", "importo"}, s.Required)
+	require.Equal(t, haropenapi.TypeNumber, s.Properties["importo"].Type)
+	require.Empty(t, s.Properties["note"].Type, "null samples only give a schema without type")
+	require.False(t, s.Properties["note"].Nullable)
+	require.Contains(t, post.Responses, "201")
+
+	get := doc.Paths["/api/v1/orders/{id}"].Get
+	require.NotNil(t, get)
+	require.Equal(t, "id", get.Parameters[0].Name)
+	require.Equal(t, haropenapi.InPath, get.Parameters[0].In)
+	require.Equal(t, haropenapi.TypeString, get.Parameters[0].Schema.Type)
+
+	rs := get.Responses["200"].Content["application/json"].Schema
+	require.Equal(t, haropenapi.TypeArray, rs.Properties["items"].Type)
+	require.Equal(t, haropenapi.TypeInteger, rs.Properties["items"].Items.Properties["qty"].Type)
+	require.Equal(t, "date-time", rs.Properties["created"].Format)
+
+	var buf bytes.Buffer
+	require.NoError(t, doc.Write(&buf, haropenapi.FormatYAML))
+	t.Log("\n" + buf.String())
+
+	doc2, err := haropenapi.ReadDocument(&buf)
+	require.NoError(t, err)
+	require.Equal(t, "postApiV1Orders", doc2.Paths["/api/v1/orders"].Post.OperationId)
+}
+
+func TestPathParams(t *testing.T) {
+	req, err := har.NewRequest(http.MethodGet, "http://localhost:3004/api/v1/customers/rossi/orders/12", nil, http.Header{}, nil, nil)
+	require.NoError(t, err)
+	req.PathParams = []har.Param{{Name: "customer", Value: "rossi"}}
+
+	doc, err := haropenapi.Generate(har.NewHAR(har.WithEntry(&har.Entry{Request: req})))
+	require.NoError(t, err)
+	require.Contains(t, doc.Paths, "/api/v1/customers/{customer}/orders/{id}")
+	require.Contains(t, doc.Paths["/api/v1/customers/{customer}/orders/{id}"].Get.Responses, "default")
+}
+
+func TestPathParamsNormalized(t *testing.T) {
+	var entries []har.BuilderOption
+	for i, pp := range [][]har.Param{nil, {{Name: "itemId", Value: "11"}}} {
+		req, err := har.NewRequest(http.MethodGet, "http://localhost:3004/api/v1/items/1"+strconv.Itoa(i), nil, http.Header{}, nil, nil)
+		require.NoError(t, err)
+		req.PathParams = pp
+		entries = append(entries, har.WithEntry(&har.Entry{Request: req}))
+	}
+
+	doc, err := haropenapi.Generate(har.NewHAR(entries...))
+	require.NoError(t, err)
+	require.Len(t, doc.Paths, 1)
+	require.Contains(t, doc.Paths, "/api/v1/items/{id}")
+	require.Equal(t, "id", doc.Paths["/api/v1/items/{id}"].Get.Parameters[0].Name)
+}
+
+func TestNullableSchema(t *testing.T) {
+	s := haropenapi.MergeSchemas(haropenapi.InferSchema(nil), haropenapi.InferSchema("a"))
+	require.Equal(t, haropenapi.TypeString, s.Type)
+	require.True(t, s.Nullable)
+
+	s = haropenapi.MergeSchemas(haropenapi.MergeSchemas(haropenapi.InferSchema(nil), haropenapi.InferSchema("a")), haropenapi.InferSchema(true))
+	require.Empty(t, s.Type)
+	require.False(t, s.Nullable)
+}
@@ -0,0 +1,151 @@
+package haropenapi
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+var uuidRegexp = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// InferSchema returns the schema of a value decoded by encoding/json. All the properties of the objects are reported as required:
+// merging the schemas of several samples with MergeSchemas keeps the ones found in every sample.
+func InferSchema(v interface{}) *Schema {
+	switch tv := v.(type) {
+	case nil:
+		return &Schema{null: true}
+	case bool:
+		return &Schema{Type: TypeBoolean}
+	case float64:
+		if tv == math.Trunc(tv) && math.Abs(tv) < 1<<53 {
+			return &Schema{Type: TypeInteger}
+		}
+		return &Schema{Type: TypeNumber}
+	case string:
+		return &Schema{Type: TypeString, Format: stringFormat(tv)}
+	case []interface{}:
+		s := &Schema{Type: TypeArray}
+		for _, item := range tv {
+			s.Items = MergeSchemas(s.Items, InferSchema(item))
+		}
+		if s.Items == nil {
+			s.Items = &Schema{}
+		}
+		return s
+	case map[string]interface{}:
+		s := &Schema{Type: TypeObject, Properties: map[string]*Schema{}}
+		for n, pv := range tv {
+			s.Properties[n] = InferSchema(pv)
+			s.Required = append(s.Required, n)
+		}
+		sort.Strings(s.Required)
+		return s
+	}
+
+	return &Schema{}
+}
+
+// InferStringSchema returns the schema of a value carried as a string such as a path or a query parameter.
+func InferStringSchema(v string) *Schema {
+	if _, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return &Schema{Type: TypeInteger}
+	}
+
+	switch v {
+	case "true", "false":
+		return &Schema{Type: TypeBoolean}
+	}
+
+	return &Schema{Type: TypeString, Format: stringFormat(v)}
+}
+
+// MergeSchemas returns a schema accepting the samples described by both the schemas. Either of the two may be nil.
+func MergeSchemas(a, b *Schema) *Schema {
+	if a == nil {
+		return b
+	}
+
+	if b == nil {
+		return a
+	}
+
+	s := &Schema{}
+	switch {
+	case a.Type == "" && isNullOnly(a):
+		s.Type, s.Format, s.Properties, s.Required, s.Items = b.Type, b.Format, b.Properties, b.Required, b.Items
+	case b.Type == "" && isNullOnly(b):
+		s.Type, s.Format, s.Properties, s.Required, s.Items = a.Type, a.Format, a.Properties, a.Required, a.Items
+	case a.Type == b.Type:
+		s.Type = a.Type
+		if a.Format == b.Format {
+			s.Format = a.Format
+		}
+
+		switch s.Type {
+		case TypeArray:
+			s.Items = MergeSchemas(a.Items, b.Items)
+		case TypeObject:
+			s.Properties = map[string]*Schema{}
+			for n, ps := range a.Properties {
+				s.Properties[n] = MergeSchemas(ps, b.Properties[n])
+			}
+			for n, ps := range b.Properties {
+				if _, ok := s.Properties[n]; !ok {
+					s.Properties[n] = ps
+				}
+			}
+			s.Required = intersect(a.Required, b.Required)
+		}
+	case isNumeric(a.Type) && isNumeric(b.Type):
+		s.Type = TypeNumber
+	}
+
+	// OAS 3.0 doesn't allow nullable without a type: the schema without a type accepts null values anyway.
+	nullable := a.Nullable || b.Nullable || a.null || b.null
+	if s.Type != "" {
+		s.Nullable = nullable
+	} else {
+		s.null = nullable && isNullOnly(a) && isNullOnly(b)
+	}
+
+	return s
+}
+
+func isNullOnly(s *Schema) bool {
+	return s.null && s.Type == "" && len(s.Properties) == 0 && s.Items == nil
+}
+
+func isNumeric(t string) bool {
+	return t == TypeNumber || t == TypeInteger
+}
+
+func intersect(a, b []string) []string {
+	var res []string
+	for _, s := range a {
+		for _, t := range b {
+			if s == t {
+				res = append(res, s)
+				break
+			}
+		}
+	}
+	return res
+}
+
+func stringFormat(s string) string {
+	if uuidRegexp.MatchString(s) {
+		return "uuid"
+	}
+
+	if _, err := time.Parse(time.RFC3339, s); err == nil {
+		return "date-time"
+	}
+
+	if _, err := time.Parse(time.DateOnly, s); err == nil {
+		return "date"
+	}
+
+	return ""
+}
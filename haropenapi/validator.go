@@ -0,0 +1,639 @@
+package haropenapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/har"
+	"io"
+	"mime"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	UnknownPath         = "unknown-path"
+	UnknownMethod       = "unknown-method"
+	UndocumentedStatus  = "undocumented-status"
+	UndocumentedContent = "undocumented-content-type"
+	MissingParameter    = "missing-parameter"
+	InvalidParameter    = "invalid-parameter"
+	MissingRequestBody  = "missing-request-body"
+	InvalidRequestBody  = "invalid-request-body"
+	InvalidResponseBody = "invalid-response-body"
+)
+
+// Violation is a non conformity of an entry of the log with respect to the document.
+type Violation struct {
+	Entry    int    `json:"entry" yaml:"entry" mapstructure:"entry"` // index of the entry in the log.
+	Method   string `json:"method" yaml:"method" mapstructure:"method"`
+	URL      string `json:"url" yaml:"url" mapstructure:"url"`
+	Kind     string `json:"kind" yaml:"kind" mapstructure:"kind"`
+	Location string `json:"location,omitempty" yaml:"location,omitempty" mapstructure:"location,omitempty"` // e.g. query.lang, response.body.items[0].qty
+	Message  string `json:"message" yaml:"message" mapstructure:"message"`
+}
+
+func (v Violation) String() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("#%d %s %s: %s", v.Entry, v.Method, v.URL, v.Kind))
+	if v.Location != "" {
+		sb.WriteString(" at " + v.Location)
+	}
+	sb.WriteString(": " + v.Message)
+	return sb.String()
+}
+
+type Report struct {
+	Entries    int         `json:"entries" yaml:"entries" mapstructure:"entries"`
+	Violations []Violation `json:"violations,omitempty" yaml:"violations,omitempty" mapstructure:"violations,omitempty"`
+}
+
+func (r *Report) Valid() bool {
+	return len(r.Violations) == 0
+}
+
+// Write prints the violations one per line followed by a summary.
+func (r *Report) Write(w io.Writer) error {
+	for _, v := range r.Violations {
+		if _, err := fmt.Fprintln(w, v.String()); err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "%d entries, %d violations\n", r.Entries, len(r.Violations))
+	return err
+}
+
+type validatorOpts struct {
+	basePaths      []string
+	requestBodies  bool
+	responseBodies bool
+}
+
+type ValidatorOption func(o *validatorOpts)
+
+// WithBasePath sets the prefix to be removed from the paths of the requests before matching the document paths.
+// By default the paths of the document servers are used.
+func WithBasePath(p string) ValidatorOption {
+	return func(o *validatorOpts) {
+		o.basePaths = append(o.basePaths, p)
+	}
+}
+
+// ValidateRequestBodies enables the schema validation of the request bodies. Enabled by default.
+func ValidateRequestBodies(b bool) ValidatorOption {
+	return func(o *validatorOpts) {
+		o.requestBodies = b
+	}
+}
+
+// ValidateResponseBodies enables the schema validation of the response bodies. Enabled by default.
+func ValidateResponseBodies(b bool) ValidatorOption {
+	return func(o *validatorOpts) {
+		o.responseBodies = b
+	}
+}
+
+// Validate checks the entries of the log against the document and reports, per entry, unknown paths and methods,
+// undocumented status codes and content types, missing or invalid parameters and body schema violations.
+// Only json bodies are checked against the schemas.
+func Validate(doc *Document, l *har.Log, opts ...ValidatorOption) *Report {
+	o := validatorOpts{requestBodies: true, responseBodies: true}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if len(o.basePaths) == 0 {
+		for _, s := range doc.Servers {
+			if u, err := url.Parse(s.URL); err == nil && strings.Trim(u.Path, "/") != "" {
+				o.basePaths = append(o.basePaths, strings.TrimSuffix(u.Path, "/"))
+			}
+		}
+	}
+
+	v := &validator{doc: doc, opts: &o, report: &Report{}}
+	if l == nil {
+		return v.report
+	}
+
+	v.report.Entries = len(l.Entries)
+	for i, e := range l.Entries {
+		if e.Request != nil {
+			v.validateEntry(i, e)
+		}
+	}
+
+	return v.report
+}
+
+type validator struct {
+	doc    *Document
+	opts   *validatorOpts
+	report *Report
+
+	entry int
+	e     *har.Entry
+}
+
+func (v *validator) add(kind, location, msg string) {
+	v.report.Violations = append(v.report.Violations, Violation{
+		Entry:    v.entry,
+		Method:   v.e.Request.Method,
+		URL:      v.e.Request.URL,
+		Kind:     kind,
+		Location: location,
+		Message:  msg,
+	})
+}
+
+func (v *validator) validateEntry(ndx int, e *har.Entry) {
+	v.entry, v.e = ndx, e
+
+	u, err := url.Parse(e.Request.URL)
+	if err != nil {
+		v.add(UnknownPath, "", err.Error())
+		return
+	}
+
+	templ, pi, pathValues := v.matchPath(u.Path)
+	if pi == nil {
+		v.add(UnknownPath, "", fmt.Sprintf("path %s not documented", u.Path))
+		return
+	}
+
+	op := pi.Operation(e.Request.Method)
+	if op == nil {
+		v.add(UnknownMethod, "", fmt.Sprintf("method %s not documented for %s", strings.ToUpper(e.Request.Method), templ))
+		return
+	}
+
+	v.validateParameters(v.parameters(pi, op), u, pathValues)
+	v.validateRequestBody(op.RequestBody)
+	if e.Response != nil && e.Response.Status != 0 {
+		v.validateResponse(op)
+	}
+}
+
+// matchPath finds the path of the document matching the request path. Literal segments are preferred over parameters.
+func (v *validator) matchPath(p string) (string, *PathItem, map[string]string) {
+	candidates := []string{p}
+	for _, bp := range v.opts.basePaths {
+		if strings.HasPrefix(p, bp) {
+			candidates = append(candidates, strings.TrimPrefix(p, bp))
+		}
+	}
+
+	bestScore := -1
+	var bestTempl string
+	var best *PathItem
+	var bestValues map[string]string
+	for _, c := range candidates {
+		segs := strings.Split(strings.Trim(c, "/"), "/")
+		for templ, pi := range v.doc.Paths {
+			values, score, ok := matchTemplate(strings.Split(strings.Trim(templ, "/"), "/"), segs)
+			if ok && (score > bestScore || (score == bestScore && templ < bestTempl)) {
+				bestScore, bestTempl, best, bestValues = score, templ, pi, values
+			}
+		}
+	}
+
+	return bestTempl, best, bestValues
+}
+
+func matchTemplate(templ, segs []string) (map[string]string, int, bool) {
+	if len(templ) != len(segs) {
+		return nil, 0, false
+	}
+
+	values := map[string]string{}
+	score := 0
+	for i, t := range templ {
+		if strings.HasPrefix(t, "{") && strings.HasSuffix(t, "}") {
+			if segs[i] == "" {
+				return nil, 0, false
+			}
+			values[strings.Trim(t, "{}")] = segs[i]
+			continue
+		}
+
+		if t != segs[i] {
+			return nil, 0, false
+		}
+		score++
+	}
+
+	return values, score, true
+}
+
+// parameters returns the parameters of the operation merged with the ones of the path item, references resolved.
+func (v *validator) parameters(pi *PathItem, op *Operation) []*Parameter {
+	var res []*Parameter
+	seen := map[string]bool{}
+	for _, params := range [][]*Parameter{op.Parameters, pi.Parameters} {
+		for _, p := range params {
+			p = v.resolveParameter(p)
+			if p == nil || seen[p.In+":"+p.Name] {
+				continue
+			}
+			seen[p.In+":"+p.Name] = true
+			res = append(res, p)
+		}
+	}
+	return res
+}
+
+func (v *validator) validateParameters(params []*Parameter, u *url.URL, pathValues map[string]string) {
+	query := u.Query()
+	if u.RawQuery == "" {
+		for _, nv := range v.e.Request.QueryString {
+			query.Add(nv.Name, nv.Value)
+		}
+	}
+
+	for _, p := range params {
+		var values []string
+		switch p.In {
+		case InPath:
+			if pv, ok := pathValues[p.Name]; ok {
+				values = []string{pv}
+			}
+		case InQuery:
+			values = query[p.Name]
+		case InHeader:
+			for _, h := range v.e.Request.Headers {
+				if strings.EqualFold(h.Name, p.Name) {
+					values = append(values, h.Value)
+				}
+			}
+		case InCookie:
+			values = v.cookieValues(p.Name)
+		}
+
+		location := p.In + "." + p.Name
+		if len(values) == 0 {
+			if p.Required || p.In == InPath {
+				v.add(MissingParameter, location, "required parameter not found")
+			}
+			continue
+		}
+
+		if p.Schema == nil {
+			continue
+		}
+
+		// unresolvable or external references are not checked.
+		s := v.resolveSchema(p.Schema)
+		if s == nil {
+			continue
+		}
+
+		if s.Type == TypeArray {
+			var arr []interface{}
+			for _, pv := range values {
+				arr = append(arr, coerce(v.resolveSchema(s.Items), pv))
+			}
+			v.validateValue(InvalidParameter, location, s, arr)
+			continue
+		}
+
+		for _, pv := range values {
+			v.validateValue(InvalidParameter, location, s, coerce(s, pv))
+		}
+	}
+}
+
+func (v *validator) cookieValues(n string) []string {
+	var values []string
+	for _, c := range v.e.Request.Cookies {
+		if c.Name == n {
+			values = append(values, c.Value)
+		}
+	}
+
+	if len(values) == 0 {
+		for _, h := range v.e.Request.Headers {
+			if strings.EqualFold(h.Name, "cookie") {
+				for _, part := range strings.Split(h.Value, ";") {
+					if cn, cv, ok := strings.Cut(strings.TrimSpace(part), "="); ok && cn == n {
+						values = append(values, cv)
+					}
+				}
+			}
+		}
+	}
+
+	return values
+}
+
+// coerce converts the string value of a parameter to the type required by the schema, if possible.
+func coerce(s *Schema, pv string) interface{} {
+	if s == nil {
+		return pv
+	}
+
+	switch s.Type {
+	case TypeInteger, TypeNumber:
+		if f, err := strconv.ParseFloat(pv, 64); err == nil {
+			return f
+		}
+	case TypeBoolean:
+		if b, err := strconv.ParseBool(pv); err == nil {
+			return b
+		}
+	}
+
+	return pv
+}
+
+func (v *validator) validateRequestBody(rb *RequestBody) {
+	if rb == nil {
+		return
+	}
+
+	po := v.e.Request.PostData
+	hasBody := po != nil && (len(po.Body()) > 0 || len(po.Params) > 0)
+
+	if !hasBody {
+		if rb.Required {
+			v.add(MissingRequestBody, "request.body", "required request body not found")
+		}
+		return
+	}
+
+	mt, ok := lookupMediaType(rb.Content, po.MimeType)
+	if !ok {
+		v.add(UndocumentedContent, "request.body", fmt.Sprintf("content type %s not documented", po.MimeType))
+		return
+	}
+
+	if v.opts.requestBodies && mt != nil {
+		v.validateBody(InvalidRequestBody, "request.body", mt, po.MimeType, po.Body())
+	}
+}
+
+func (v *validator) validateResponse(op *Operation) {
+	status := v.e.Response.Status
+	r := lookupResponse(op.Responses, status)
+	if r == nil {
+		v.add(UndocumentedStatus, "response.status", fmt.Sprintf("status %d not documented", status))
+		return
+	}
+
+	c := v.e.Response.Content
+	if c == nil || len(c.Body()) == 0 || len(r.Content) == 0 {
+		return
+	}
+
+	mt, ok := lookupMediaType(r.Content, c.MimeType)
+	if !ok {
+		v.add(UndocumentedContent, "response.body", fmt.Sprintf("content type %s not documented for status %d", c.MimeType, status))
+		return
+	}
+
+	if v.opts.responseBodies && mt != nil {
+		v.validateBody(InvalidResponseBody, "response.body", mt, c.MimeType, c.Body())
+	}
+}
+
+func (v *validator) validateBody(kind, location string, mt *MediaType, mimeType string, body []byte) {
+	if mt.Schema == nil {
+		return
+	}
+
+	ct, _, _ := mime.ParseMediaType(mimeType)
+	if !isJsonMediaType(ct) {
+		return
+	}
+
+	var val interface{}
+	if err := json.Unmarshal(body, &val); err != nil {
+		v.add(kind, location, "invalid json: "+err.Error())
+		return
+	}
+
+	v.validateValue(kind, location, mt.Schema, val)
+}
+
+// lookupResponse finds the response of the status code: the exact code first, then the range (e.g. 2XX) and the default.
+func lookupResponse(responses map[string]*Response, status int) *Response {
+	code := strconv.Itoa(status)
+	if r, ok := responses[code]; ok {
+		return r
+	}
+
+	for k, r := range responses {
+		if strings.EqualFold(k, code[:1]+"XX") {
+			return r
+		}
+	}
+
+	return responses["default"]
+}
+
+// lookupMediaType finds the media type matching the content type: the exact one first, then type/* and */*.
+func lookupMediaType(content map[string]*MediaType, contentType string) (*MediaType, bool) {
+	if len(content) == 0 {
+		return nil, true
+	}
+
+	ct, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		ct = strings.ToLower(strings.TrimSpace(contentType))
+	}
+
+	keys := make([]string, 0, len(content))
+	for k := range content {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, candidate := range []string{ct, strings.SplitN(ct, "/", 2)[0] + "/*", "*/*"} {
+		for _, k := range keys {
+			if kt, _, err := mime.ParseMediaType(k); err == nil && kt == candidate {
+				return content[k], true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+const componentsSchemasPrefix = "#/components/schemas/"
+const componentsParametersPrefix = "#/components/parameters/"
+
+func (v *validator) resolveSchema(s *Schema) *Schema {
+	for i := 0; s != nil && s.Ref != "" && i < 32; i++ {
+		if v.doc.Components == nil || !strings.HasPrefix(s.Ref, componentsSchemasPrefix) {
+			return nil
+		}
+		s = v.doc.Components.Schemas[strings.TrimPrefix(s.Ref, componentsSchemasPrefix)]
+	}
+	return s
+}
+
+func (v *validator) resolveParameter(p *Parameter) *Parameter {
+	if p == nil || p.Ref == "" {
+		return p
+	}
+
+	if v.doc.Components == nil || !strings.HasPrefix(p.Ref, componentsParametersPrefix) {
+		return nil
+	}
+	return v.doc.Components.Parameters[strings.TrimPrefix(p.Ref, componentsParametersPrefix)]
+}
+
+func (v *validator) validateValue(kind, location string, s *Schema, val interface{}) {
+	for _, msg := range v.schemaErrors(s, val, location) {
+		v.add(kind, msg.location, msg.message)
+	}
+}
+
+type schemaError struct {
+	location string
+	message  string
+}
+
+// schemaErrors returns the violations of the value with respect to the schema. Unresolvable references are not checked.
+func (v *validator) schemaErrors(s *Schema, val interface{}, location string) []schemaError {
+	if s == nil {
+		return nil
+	}
+
+	if s.Ref != "" {
+		if s = v.resolveSchema(s); s == nil {
+			return nil
+		}
+	}
+
+	if val == nil {
+		if s.Nullable || s.Type == "" {
+			return nil
+		}
+		return []schemaError{{location, fmt.Sprintf("null value, expected %s", s.Type)}}
+	}
+
+	var errs []schemaError
+	for _, sub := range s.AllOf {
+		errs = append(errs, v.schemaErrors(sub, val, location)...)
+	}
+
+	if len(s.AnyOf) > 0 && v.matching(s.AnyOf, val, location) == 0 {
+		errs = append(errs, schemaError{location, "value doesn't match any of the anyOf schemas"})
+	}
+
+	if len(s.OneOf) > 0 {
+		if n := v.matching(s.OneOf, val, location); n != 1 {
+			errs = append(errs, schemaError{location, fmt.Sprintf("value matches %d of the oneOf schemas, expected exactly one", n)})
+		}
+	}
+
+	if s.Type != "" && !hasType(val, s.Type) {
+		return append(errs, schemaError{location, fmt.Sprintf("value of type %s, expected %s", jsonType(val), s.Type)})
+	}
+
+	if len(s.Enum) > 0 && !inEnum(val, s.Enum) {
+		errs = append(errs, schemaError{location, fmt.Sprintf("value %v not in enum %v", val, s.Enum)})
+	}
+
+	if str, ok := val.(string); ok {
+		if f := stringFormat(str); (s.Format == "uuid" || s.Format == "date-time" || s.Format == "date") && f != s.Format {
+			errs = append(errs, schemaError{location, fmt.Sprintf("value %q is not a valid %s", str, s.Format)})
+		}
+	}
+
+	switch tv := val.(type) {
+	case map[string]interface{}:
+		for _, n := range s.Required {
+			if _, ok := tv[n]; !ok {
+				errs = append(errs, schemaError{location + "." + n, "required property not found"})
+			}
+		}
+
+		names := make([]string, 0, len(tv))
+		for n := range tv {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+
+		for _, n := range names {
+			if ps, ok := s.Properties[n]; ok {
+				errs = append(errs, v.schemaErrors(ps, tv[n], location+"."+n)...)
+				continue
+			}
+
+			switch ap := s.AdditionalProperties.(type) {
+			case bool:
+				if !ap {
+					errs = append(errs, schemaError{location + "." + n, "additional property not allowed"})
+				}
+			case *Schema:
+				errs = append(errs, v.schemaErrors(ap, tv[n], location+"."+n)...)
+			case map[string]interface{}:
+				// as decoded from the document.
+				var aps Schema
+				if b, err := json.Marshal(ap); err == nil && json.Unmarshal(b, &aps) == nil {
+					errs = append(errs, v.schemaErrors(&aps, tv[n], location+"."+n)...)
+				}
+			}
+		}
+
+	case []interface{}:
+		if s.Items != nil {
+			for i, item := range tv {
+				errs = append(errs, v.schemaErrors(s.Items, item, fmt.Sprintf("%s[%d]", location, i))...)
+			}
+		}
+	}
+
+	return errs
+}
+
+func (v *validator) matching(schemas []*Schema, val interface{}, location string) int {
+	n := 0
+	for _, sub := range schemas {
+		if len(v.schemaErrors(sub, val, location)) == 0 {
+			n++
+		}
+	}
+	return n
+}
+
+func hasType(val interface{}, t string) bool {
+	switch t {
+	case TypeInteger:
+		f, ok := val.(float64)
+		return ok && f == float64(int64(f))
+	case TypeNumber:
+		_, ok := val.(float64)
+		return ok
+	}
+	return jsonType(val) == t
+}
+
+func jsonType(val interface{}) string {
+	switch val.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return TypeBoolean
+	case float64:
+		return TypeNumber
+	case string:
+		return TypeString
+	case []interface{}:
+		return TypeArray
+	case map[string]interface{}:
+		return TypeObject
+	}
+	return fmt.Sprintf("%T", val)
+}
+
+func inEnum(val interface{}, enum []interface{}) bool {
+	for _, e := range enum {
+		if fmt.Sprint(e) == fmt.Sprint(val) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,161 @@
+package haropenapi_test
+
+import (
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/har"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/haropenapi"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+const testSpec = `
+openapi: 3.0.3
+info:
+  title: orders
+  version: 1.0.0
+servers:
+  - url: http://localhost:3004/api/v1
+paths:
+  /orders:
+    post:
+      parameters:
+        - name: lang
+          in: query
+          required: true
+          schema:
+            type: string
+            enum: [it, en]
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Order'
+      responses:
+        "201":
+          description: Created
+  /orders/{id}:
+    parameters:
+      - name: id
+        in: path
+        required: true
+        schema:
+          type: integer
+    get:
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [items]
+                properties:
+                  items:
+                    type: array
+                    items:
+                      type: object
+                      additionalProperties: false
+                      properties:
+                        sku:
+                          type: string
+                        qty:
+                          type: integer
+components:
+  schemas:
+    Order:
+      type: object
+      required: [canale, importo]
+      properties:
+        canale:
+          type: string
+        importo:
+          type: number
+`
+
+func TestValidate(t *testing.T) {
+	doc, err := haropenapi.ReadDocument(strings.NewReader(testSpec))
+	require.NoError(t, err)
+
+	h := testHAR(t)
+	report := haropenapi.Validate(doc, h.Log)
+	for _, v := range report.Violations {
+		t.Log(v.String())
+	}
+
+	// the first two entries conform, the uuid of the last one is not an integer.
+	require.Len(t, report.Violations, 1)
+	require.Equal(t, 3, report.Violations[0].Entry)
+	require.Equal(t, haropenapi.InvalidParameter, report.Violations[0].Kind)
+	require.Equal(t, "path.id", report.Violations[0].Location)
+
+	newEntry := func(method, u, body string, status int, respBody string) *har.Entry {
+		req, err := har.NewRequest(method, u, []byte(body), http.Header{"Content-Type": []string{"application/json"}}, nil, nil)
+		require.NoError(t, err)
+		return &har.Entry{Request: req, Response: har.NewResponse(status, "", "application/json", []byte(respBody), nil)}
+	}
+
+	l := &har.Log{Entries: []*har.Entry{
+		newEntry(http.MethodPost, "http://localhost:3004/api/v1/orders?lang=fr", `{"canale":"APPP"}`, 400, ""),
+		newEntry(http.MethodPost, "http://localhost:3004/api/v1/orders", "", 201, ""),
+		newEntry(http.MethodGet, "http://localhost:3004/api/v1/orders/12", "", 200, `{"items":[{"sku":"a","qty":1.5,"color":"red"}]}`),
+		newEntry(http.MethodDelete, "http://localhost:3004/api/v1/orders/12", "", 204, ""),
+		newEntry(http.MethodGet, "http://localhost:3004/api/v1/customers", "", 200, ""),
+	}}
+
+	report = haropenapi.Validate(doc, l)
+	for _, v := range report.Violations {
+		t.Log(v.String())
+	}
+
+	kinds := map[string][]string{}
+	for _, v := range report.Violations {
+		kinds[v.Kind] = append(kinds[v.Kind], v.Location)
+	}
+
+	require.Equal(t, []string{"query.lang"}, kinds[haropenapi.InvalidParameter])
+	require.Equal(t, []string{"request.body.importo"}, kinds[haropenapi.InvalidRequestBody])
+	require.Equal(t, []string{"response.status"}, kinds[haropenapi.UndocumentedStatus])
+	require.Equal(t, []string{"query.lang"}, kinds[haropenapi.MissingParameter])
+	require.Equal(t, []string{"request.body"}, kinds[haropenapi.MissingRequestBody])
+	require.Equal(t, []string{"response.body.items[0].color", "response.body.items[0].qty"}, kinds[haropenapi.InvalidResponseBody])
+	require.Len(t, kinds[haropenapi.UnknownMethod], 1)
+	require.Len(t, kinds[haropenapi.UnknownPath], 1)
+	require.False(t, report.Valid())
+}
+
+func TestValidateUnresolvedParameterRef(t *testing.T) {
+	const spec = `
+openapi: 3.0.3
+info:
+  title: orders
+  version: 1.0.0
+paths:
+  /orders:
+    get:
+      parameters:
+        - name: lang
+          in: query
+          schema:
+            $ref: 'common.yaml#/components/schemas/Lang'
+        - name: page
+          in: query
+          schema:
+            type: array
+            items:
+              $ref: '#/components/schemas/Missing'
+      responses:
+        "200":
+          description: OK
+`
+	doc, err := haropenapi.ReadDocument(strings.NewReader(spec))
+	require.NoError(t, err)
+
+	req, err := har.NewRequest(http.MethodGet, "http://localhost:3004/orders?lang=it&page=x", nil, http.Header{}, har.NameValuePairs{{Name: "lang", Value: "it"}, {Name: "page", Value: "x"}}, nil)
+	require.NoError(t, err)
+	l := &har.Log{Entries: []*har.Entry{{Request: req, Response: har.NewResponse(200, "OK", "application/json", nil, nil)}}}
+
+	report := haropenapi.Validate(doc, l)
+	require.True(t, report.Valid(), "unresolvable references are not checked")
+}
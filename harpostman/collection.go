@@ -0,0 +1,101 @@
+package harpostman
+
+// Types of the Postman Collection Format v2.1. Only the subset needed to carry the har data is modelled.
+//
+// See: https://schema.getpostman.com/json/collection/v2.1.0/collection.json
+
+const (
+	SchemaV21 = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+)
+
+type Collection struct {
+	Info     Info       `json:"info" yaml:"info" mapstructure:"info"`
+	Item     []*Item    `json:"item" yaml:"item" mapstructure:"item"`
+	Variable []Variable `json:"variable,omitempty" yaml:"variable,omitempty" mapstructure:"variable,omitempty"`
+}
+
+type Info struct {
+	PostmanId   string `json:"_postman_id,omitempty" yaml:"_postman_id,omitempty" mapstructure:"_postman_id,omitempty"`
+	Name        string `json:"name" yaml:"name" mapstructure:"name"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty" mapstructure:"description,omitempty"`
+	Schema      string `json:"schema" yaml:"schema" mapstructure:"schema"`
+}
+
+// Item is either a request or, when Item is populated, a folder.
+type Item struct {
+	Name     string      `json:"name" yaml:"name" mapstructure:"name"`
+	Item     []*Item     `json:"item,omitempty" yaml:"item,omitempty" mapstructure:"item,omitempty"`
+	Request  *Request    `json:"request,omitempty" yaml:"request,omitempty" mapstructure:"request,omitempty"`
+	Response []*Response `json:"response,omitempty" yaml:"response,omitempty" mapstructure:"response,omitempty"`
+}
+
+type Request struct {
+	Method string   `json:"method" yaml:"method" mapstructure:"method"`
+	Header []Header `json:"header" yaml:"header" mapstructure:"header"`
+	Body   *Body    `json:"body,omitempty" yaml:"body,omitempty" mapstructure:"body,omitempty"`
+	URL    URL      `json:"url" yaml:"url" mapstructure:"url"`
+}
+
+type Header struct {
+	Key   string `json:"key" yaml:"key" mapstructure:"key"`
+	Value string `json:"value" yaml:"value" mapstructure:"value"`
+}
+
+type URL struct {
+	Raw   string   `json:"raw" yaml:"raw" mapstructure:"raw"`
+	Host  []string `json:"host,omitempty" yaml:"host,omitempty" mapstructure:"host,omitempty"`
+	Path  []string `json:"path,omitempty" yaml:"path,omitempty" mapstructure:"path,omitempty"`
+	Query []Query  `json:"query,omitempty" yaml:"query,omitempty" mapstructure:"query,omitempty"`
+}
+
+type Query struct {
+	Key   string `json:"key" yaml:"key" mapstructure:"key"`
+	Value string `json:"value" yaml:"value" mapstructure:"value"`
+}
+
+const (
+	BodyModeRaw        = "raw"
+	BodyModeUrlEncoded = "urlencoded"
+	BodyModeFormData   = "formdata"
+)
+
+type Body struct {
+	Mode       string       `json:"mode" yaml:"mode" mapstructure:"mode"`
+	Raw        string       `json:"raw,omitempty" yaml:"raw,omitempty" mapstructure:"raw,omitempty"`
+	UrlEncoded []FormParam  `json:"urlencoded,omitempty" yaml:"urlencoded,omitempty" mapstructure:"urlencoded,omitempty"`
+	FormData   []FormParam  `json:"formdata,omitempty" yaml:"formdata,omitempty" mapstructure:"formdata,omitempty"`
+	Options    *BodyOptions `json:"options,omitempty" yaml:"options,omitempty" mapstructure:"options,omitempty"`
+}
+
+type BodyOptions struct {
+	Raw RawOptions `json:"raw" yaml:"raw" mapstructure:"raw"`
+}
+
+type RawOptions struct {
+	Language string `json:"language" yaml:"language" mapstructure:"language"`
+}
+
+type FormParam struct {
+	Key         string `json:"key" yaml:"key" mapstructure:"key"`
+	Value       string `json:"value,omitempty" yaml:"value,omitempty" mapstructure:"value,omitempty"`
+	Type        string `json:"type" yaml:"type" mapstructure:"type"` // text or file
+	Src         string `json:"src,omitempty" yaml:"src,omitempty" mapstructure:"src,omitempty"`
+	ContentType string `json:"contentType,omitempty" yaml:"contentType,omitempty" mapstructure:"contentType,omitempty"`
+}
+
+// Response is a saved example response.
+type Response struct {
+	Name                   string   `json:"name" yaml:"name" mapstructure:"name"`
+	OriginalRequest        *Request `json:"originalRequest,omitempty" yaml:"originalRequest,omitempty" mapstructure:"originalRequest,omitempty"`
+	Status                 string   `json:"status" yaml:"status" mapstructure:"status"`
+	Code                   int      `json:"code" yaml:"code" mapstructure:"code"`
+	PostmanPreviewLanguage string   `json:"_postman_previewlanguage,omitempty" yaml:"_postman_previewlanguage,omitempty" mapstructure:"_postman_previewlanguage,omitempty"`
+	Header                 []Header `json:"header" yaml:"header" mapstructure:"header"`
+	Body                   string   `json:"body" yaml:"body" mapstructure:"body"`
+}
+
+type Variable struct {
+	Key   string `json:"key" yaml:"key" mapstructure:"key"`
+	Value string `json:"value" yaml:"value" mapstructure:"value"`
+	Type  string `json:"type,omitempty" yaml:"type,omitempty" mapstructure:"type,omitempty"`
+}
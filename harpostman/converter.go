@@ -0,0 +1,347 @@
+package harpostman
+
+import (
+	"fmt"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/har"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/hartracing"
+	"github.com/google/uuid"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Grouping tells how the requests are arranged in folders.
+type Grouping int
+
+const (
+	GroupByHost Grouping = iota
+	GroupByTraceId
+	GroupNone
+)
+
+func ParseGrouping(s string) (Grouping, error) {
+	switch strings.ToLower(s) {
+	case "", "host":
+		return GroupByHost, nil
+	case "trace", "trace-id":
+		return GroupByTraceId, nil
+	case "none":
+		return GroupNone, nil
+	}
+
+	return GroupByHost, fmt.Errorf("unknown grouping %s: wanted one of host, trace, none", s)
+}
+
+const (
+	DefaultHostVariable = "baseUrl"
+)
+
+type converterOpts struct {
+	name     string
+	grouping Grouping
+	examples bool
+}
+
+type Option func(o *converterOpts)
+
+func WithName(n string) Option {
+	return func(o *converterOpts) {
+		o.name = n
+	}
+}
+
+func WithGrouping(g Grouping) Option {
+	return func(o *converterOpts) {
+		o.grouping = g
+	}
+}
+
+// WithExamples saves the recorded responses as examples of the requests. Enabled by default.
+func WithExamples(b bool) Option {
+	return func(o *converterOpts) {
+		o.examples = b
+	}
+}
+
+// NewCollection converts the har into a Postman v2.1 collection. The scheme and host of the urls are extracted into
+// collection variables: baseUrl if the har targets a single host, a variable per host otherwise.
+func NewCollection(h *har.HAR, opts ...Option) (*Collection, error) {
+	o := converterOpts{name: "har", grouping: GroupByHost, examples: true}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	c := &Collection{
+		Info: Info{PostmanId: uuid.NewString(), Name: o.name, Schema: SchemaV21},
+		Item: []*Item{},
+	}
+
+	if h == nil || h.Log == nil {
+		return c, nil
+	}
+
+	if h.Log.Comment != "" {
+		c.Info.Description = h.Log.Comment
+	}
+
+	vars := newHostVariables(h.Log.Entries)
+	c.Variable = vars.variables()
+
+	folders := map[string]*Item{}
+	for _, e := range h.Log.Entries {
+		if e.Request == nil {
+			continue
+		}
+
+		item, err := o.newItem(e, vars)
+		if err != nil {
+			return nil, err
+		}
+
+		folderName := o.folderName(e)
+		if folderName == "" {
+			c.Item = append(c.Item, item)
+			continue
+		}
+
+		f, ok := folders[folderName]
+		if !ok {
+			f = &Item{Name: folderName}
+			folders[folderName] = f
+			c.Item = append(c.Item, f)
+		}
+		f.Item = append(f.Item, item)
+	}
+
+	return c, nil
+}
+
+func (o *converterOpts) folderName(e *har.Entry) string {
+	switch o.grouping {
+	case GroupByHost:
+		if u, err := url.Parse(e.Request.URL); err == nil && u.Host != "" {
+			return u.Host
+		}
+		return "no-host"
+	case GroupByTraceId:
+		if e.TraceId == "" {
+			return "no-trace"
+		}
+		if sctx, err := hartracing.ExtractSimpleSpanContextFromString(e.TraceId); err == nil {
+			return sctx.LogId
+		}
+		return e.TraceId
+	}
+	return ""
+}
+
+func (o *converterOpts) newItem(e *har.Entry, vars *hostVariables) (*Item, error) {
+	req, err := newRequest(e.Request, vars)
+	if err != nil {
+		return nil, err
+	}
+
+	name := e.Request.Method + " " + strings.Join(append([]string{""}, req.URL.Path...), "/")
+	if e.Comment != "" {
+		name = e.Comment
+	}
+
+	item := &Item{Name: name, Request: req, Response: []*Response{}}
+	if o.examples && e.Response != nil {
+		item.Response = append(item.Response, newResponse(e.Response, req))
+	}
+
+	return item, nil
+}
+
+func newRequest(r *har.Request, vars *hostVariables) (*Request, error) {
+	u, err := url.Parse(r.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &Request{Method: strings.ToUpper(r.Method), Header: []Header{}}
+	for _, h := range r.Headers {
+		if n := strings.ToLower(h.Name); n == "content-length" || n == "host" || strings.HasPrefix(n, ":") {
+			continue
+		}
+		req.Header = append(req.Header, Header{Key: h.Name, Value: h.Value})
+	}
+
+	query := u.Query()
+	if u.RawQuery == "" {
+		for _, nv := range r.QueryString {
+			query.Add(nv.Name, nv.Value)
+		}
+	}
+
+	p := u.EscapedPath()
+	req.URL = URL{Raw: p}
+	if u.Host != "" {
+		v := "{{" + vars.name(u) + "}}"
+		req.URL.Host = []string{v}
+		req.URL.Raw = v + p
+	}
+
+	for _, s := range strings.Split(strings.TrimPrefix(p, "/"), "/") {
+		if s != "" {
+			req.URL.Path = append(req.URL.Path, s)
+		}
+	}
+
+	for _, nv := range har.NameValuePairsFromValues(query) {
+		req.URL.Query = append(req.URL.Query, Query{Key: nv.Name, Value: nv.Value})
+	}
+	if len(query) > 0 {
+		req.URL.Raw += "?" + query.Encode()
+	}
+
+	req.Body = newBody(r.PostData)
+	return req, nil
+}
+
+func newBody(po *har.PostData) *Body {
+	if po == nil {
+		return nil
+	}
+
+	mt, _, _ := mime.ParseMediaType(po.MimeType)
+	if b := po.Body(); len(b) > 0 {
+		body := &Body{Mode: BodyModeRaw, Raw: string(b)}
+		if lang := rawLanguage(mt); lang != "" {
+			body.Options = &BodyOptions{Raw: RawOptions{Language: lang}}
+		}
+		return body
+	}
+
+	if len(po.Params) == 0 {
+		return nil
+	}
+
+	switch mt {
+	case "application/x-www-form-urlencoded":
+		body := &Body{Mode: BodyModeUrlEncoded}
+		for _, p := range po.Params {
+			body.UrlEncoded = append(body.UrlEncoded, FormParam{Key: p.Name, Value: p.Value, Type: "text"})
+		}
+		return body
+	case "multipart/form-data":
+		body := &Body{Mode: BodyModeFormData}
+		for _, p := range po.Params {
+			if p.FileName != "" {
+				body.FormData = append(body.FormData, FormParam{Key: p.Name, Type: "file", Src: p.FileName, ContentType: p.ContentType})
+			} else {
+				body.FormData = append(body.FormData, FormParam{Key: p.Name, Value: p.Value, Type: "text"})
+			}
+		}
+		return body
+	}
+
+	return nil
+}
+
+func rawLanguage(mt string) string {
+	switch {
+	case strings.Contains(mt, "json"):
+		return "json"
+	case strings.Contains(mt, "xml"):
+		return "xml"
+	case strings.Contains(mt, "html"):
+		return "html"
+	case strings.Contains(mt, "javascript"):
+		return "javascript"
+	case strings.HasPrefix(mt, "text/"):
+		return "text"
+	}
+	return ""
+}
+
+func newResponse(r *har.Response, req *Request) *Response {
+	// the status text of the responses built from net/http ones carries the code as well.
+	status := strings.TrimSpace(strings.TrimPrefix(r.StatusText, strconv.Itoa(r.Status)))
+	if status == "" {
+		status = http.StatusText(r.Status)
+	}
+
+	resp := &Response{
+		Name:            fmt.Sprintf("%d %s", r.Status, status),
+		OriginalRequest: req,
+		Status:          status,
+		Code:            r.Status,
+		Header:          []Header{},
+	}
+
+	for _, h := range r.Headers {
+		resp.Header = append(resp.Header, Header{Key: h.Name, Value: h.Value})
+	}
+
+	if r.Content != nil {
+		resp.Body = string(r.Content.Body())
+		mt, _, _ := mime.ParseMediaType(r.Content.MimeType)
+		resp.PostmanPreviewLanguage = rawLanguage(mt)
+	}
+
+	return resp
+}
+
+var nonWordRegexp = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// hostVariables maps scheme and host of the urls to the collection variables.
+type hostVariables struct {
+	names  map[string]string
+	values []Variable
+}
+
+func newHostVariables(entries []*har.Entry) *hostVariables {
+	var origins []string
+	seen := map[string]bool{}
+	for _, e := range entries {
+		if e.Request == nil {
+			continue
+		}
+		if u, err := url.Parse(e.Request.URL); err == nil && u.Host != "" && !seen[origin(u)] {
+			seen[origin(u)] = true
+			origins = append(origins, origin(u))
+		}
+	}
+
+	hv := &hostVariables{names: map[string]string{}}
+	for _, o := range origins {
+		n := DefaultHostVariable
+		if len(origins) > 1 {
+			u, _ := url.Parse(o)
+			n = strings.Trim(nonWordRegexp.ReplaceAllString(u.Host, "_"), "_")
+			for hv.taken(n) {
+				n += "_"
+			}
+		}
+		hv.names[o] = n
+		hv.values = append(hv.values, Variable{Key: n, Value: o, Type: "string"})
+	}
+
+	return hv
+}
+
+func (hv *hostVariables) taken(n string) bool {
+	for _, v := range hv.values {
+		if v.Key == n {
+			return true
+		}
+	}
+	return false
+}
+
+func (hv *hostVariables) name(u *url.URL) string {
+	return hv.names[origin(u)]
+}
+
+func (hv *hostVariables) variables() []Variable {
+	return hv.values
+}
+
+func origin(u *url.URL) string {
+	return u.Scheme + "://" + u.Host
+}
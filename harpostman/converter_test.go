@@ -0,0 +1,67 @@
+package harpostman_test
+
+import (
+	"encoding/json"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/har"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/harpostman"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+)
+
+func TestNewCollection(t *testing.T) {
+	req1, err := har.NewRequest(http.MethodPost, "http://localhost:3004/api/v1/orc-01?lang=it", []byte(`{"canale":"APPP"}`), http.Header{"Content-Type": []string{"application/json"}}, nil, nil)
+	require.NoError(t, err)
+	req2, err := har.NewRequest(http.MethodGet, "https://example.com/api/v1/endpoint-01/10724279", nil, http.Header{"Accept": []string{"application/json"}}, nil, nil)
+	require.NoError(t, err)
+
+	h := har.NewHAR(
+		har.WithEntry(&har.Entry{Request: req1, Response: har.NewResponse(200, "OK", "application/json", []byte(`{"esito":"OK"}`), nil), TraceId: "log-01:log-01:span-01:1"}),
+		har.WithEntry(&har.Entry{Request: req2, Response: har.NewResponse(404, "Not Found", "application/json", nil, nil), TraceId: "log-01:log-01:span-02:1"}),
+	)
+
+	c, err := harpostman.NewCollection(h, harpostman.WithName("orc"))
+	require.NoError(t, err)
+	require.Equal(t, harpostman.SchemaV21, c.Info.Schema)
+	require.Len(t, c.Variable, 2)
+	require.Equal(t, harpostman.Variable{Key: "localhost_3004", Value: "http://localhost:3004", Type: "string"}, c.Variable[0])
+
+	require.Len(t, c.Item, 2)
+	require.Equal(t, "localhost:3004", c.Item[0].Name)
+	item := c.Item[0].Item[0]
+	require.Equal(t, "{{localhost_3004}}/api/v1/orc-01?lang=it", item.Request.URL.Raw)
+	require.Equal(t, []string{"api", "v1", "orc-01"}, item.Request.URL.Path)
+	require.Equal(t, []harpostman.Query{{Key: "lang", Value: "it"}}, item.Request.URL.Query)
+	require.Equal(t, harpostman.BodyModeRaw, item.Request.Body.Mode)
+	require.Equal(t, `{"canale":"APPP"}`, item.Request.Body.Raw)
+	require.Equal(t, "json", item.Request.Body.Options.Raw.Language)
+	require.Len(t, item.Response, 1)
+	require.Equal(t, 200, item.Response[0].Code)
+	require.Equal(t, `{"esito":"OK"}`, item.Response[0].Body)
+
+	c, err = harpostman.NewCollection(h, harpostman.WithGrouping(harpostman.GroupByTraceId), harpostman.WithExamples(false))
+	require.NoError(t, err)
+	require.Len(t, c.Item, 1)
+	require.Equal(t, "log-01", c.Item[0].Name)
+	require.Len(t, c.Item[0].Item, 2)
+	require.Empty(t, c.Item[0].Item[1].Response)
+
+	b, err := json.MarshalIndent(c, "", "  ")
+	require.NoError(t, err)
+	t.Log(string(b))
+}
+
+func TestNewCollectionFromHttpResponse(t *testing.T) {
+	req, err := har.NewRequest(http.MethodGet, "http://localhost:3004/api/v1/files/a%2Fb", nil, http.Header{}, nil, nil)
+	require.NoError(t, err)
+	resp, err := har.NewResponseFromHttpResponse(&http.Response{StatusCode: http.StatusOK, Status: "200 OK", Header: http.Header{}, Body: http.NoBody})
+	require.NoError(t, err)
+
+	c, err := harpostman.NewCollection(har.NewHAR(har.WithEntry(&har.Entry{Request: req, Response: resp})))
+	require.NoError(t, err)
+
+	item := c.Item[0].Item[0]
+	require.Equal(t, "{{baseUrl}}/api/v1/files/a%2Fb", item.Request.URL.Raw)
+	require.Equal(t, "200 OK", item.Response[0].Name)
+	require.Equal(t, "OK", item.Response[0].Status)
+}
@@ -0,0 +1,229 @@
+package harreplay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/har"
+	"github.com/rs/zerolog/log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Replayer re-sends the entries of a har against a target service and records the new exchanges.
+type Replayer struct {
+	baseURL        *url.URL
+	client         *http.Client
+	preserveTiming bool
+	speed          float64
+	stopOnError    bool
+	filter         func(e *har.Entry) bool
+	headers        http.Header
+}
+
+type Option func(r *Replayer)
+
+// WithHttpClient sets the client used to send the requests. Defaults to a client not following redirects.
+func WithHttpClient(c *http.Client) Option {
+	return func(r *Replayer) {
+		r.client = c
+	}
+}
+
+// WithPreserveTiming waits, before sending each request, the time elapsed between the original entries.
+// The speed factor scales the waits (2 replays twice as fast); values <= 0 mean 1.
+func WithPreserveTiming(b bool, speed float64) Option {
+	return func(r *Replayer) {
+		r.preserveTiming = b
+		r.speed = speed
+	}
+}
+
+// WithStopOnError stops the replay at the first request failing at transport level. By default the failure is recorded and the replay goes on.
+func WithStopOnError(b bool) Option {
+	return func(r *Replayer) {
+		r.stopOnError = b
+	}
+}
+
+// WithFilter replays only the entries the filter accepts.
+func WithFilter(f func(e *har.Entry) bool) Option {
+	return func(r *Replayer) {
+		r.filter = f
+	}
+}
+
+// WithHeader sets a header on every replayed request, overriding the recorded one.
+func WithHeader(n, v string) Option {
+	return func(r *Replayer) {
+		r.headers.Set(n, v)
+	}
+}
+
+// NewReplayer creates a replayer sending the requests to baseURL: scheme and host of the recorded urls are replaced
+// and the base path, if any, is prepended to the recorded path.
+func NewReplayer(baseURL string, opts ...Option) (*Replayer, error) {
+	const semLogContext = "har-replay::new"
+
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		log.Error().Err(err).Str("base-url", baseURL).Msg(semLogContext)
+		return nil, err
+	}
+
+	if u.Scheme == "" || u.Host == "" {
+		err = fmt.Errorf("base url %s must be absolute", baseURL)
+		log.Error().Err(err).Msg(semLogContext)
+		return nil, err
+	}
+
+	r := &Replayer{
+		baseURL: u,
+		headers: http.Header{},
+		client: &http.Client{
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+
+	for _, o := range opts {
+		o(r)
+	}
+
+	if r.speed <= 0 {
+		r.speed = 1
+	}
+
+	return r, nil
+}
+
+// Replay sends the entries of the har in order and returns a new har with the recorded exchanges.
+func (r *Replayer) Replay(ctx context.Context, h *har.HAR) (*har.HAR, error) {
+	const semLogContext = "har-replay::replay"
+
+	out := har.NewHAR(har.WithCreator("tpm-har-replay", "1.0"), har.WithComment(fmt.Sprintf("replay against %s", r.baseURL.String())))
+	if h == nil || h.Log == nil {
+		return out, nil
+	}
+
+	var prevStart time.Time
+	var prevSent time.Time
+	for i, e := range h.Log.Entries {
+		if e.Request == nil || (r.filter != nil && !r.filter(e)) {
+			continue
+		}
+
+		if r.preserveTiming {
+			start := entryStartTime(e)
+			if !prevStart.IsZero() && !start.IsZero() && start.After(prevStart) {
+				wait := time.Duration(float64(start.Sub(prevStart))/r.speed) - time.Since(prevSent)
+				if err := sleep(ctx, wait); err != nil {
+					return out, err
+				}
+			}
+			prevStart = start
+			prevSent = time.Now()
+		}
+
+		ne, err := r.ReplayEntry(ctx, e)
+		if err != nil {
+			log.Error().Err(err).Int("entry", i).Msg(semLogContext)
+			if r.stopOnError || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+				return out, err
+			}
+		}
+
+		if ne != nil {
+			out.Log.Entries = append(out.Log.Entries, ne)
+		}
+	}
+
+	return out, nil
+}
+
+// ReplayEntry sends the request of the entry and returns the recorded exchange. On transport errors the returned entry
+// has no response and carries the error in the comment.
+func (r *Replayer) ReplayEntry(ctx context.Context, e *har.Entry) (*har.Entry, error) {
+	req, err := e.Request.ToHttpRequest(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.rewriteURL(req)
+	for n, v := range r.headers {
+		req.Header[n] = v
+	}
+
+	harReq, err := har.NewRequestFromHttpRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	ne := &har.Entry{
+		StartedDateTime: time.Now().Format(time.RFC3339Nano),
+		StartDateTimeTm: time.Now(),
+		Request:         harReq,
+		Timings:         &har.Timings{Blocked: -1, DNS: -1, Connect: -1, Ssl: -1},
+		Comment:         e.Comment,
+		TraceId:         e.TraceId,
+	}
+
+	resp, err := r.client.Do(req)
+	ne.Time = float64(time.Since(ne.StartDateTimeTm).Milliseconds())
+	ne.Timings.Wait = ne.Time
+	if err != nil {
+		ne.Comment = strings.TrimSpace(ne.Comment + " replay error: " + err.Error())
+		return ne, err
+	}
+	defer resp.Body.Close()
+
+	ne.Response, err = har.NewResponseFromHttpResponse(resp)
+	ne.Time = float64(time.Since(ne.StartDateTimeTm).Milliseconds())
+	ne.Timings.Wait = ne.Time
+	return ne, err
+}
+
+func (r *Replayer) rewriteURL(req *http.Request) {
+	req.URL.Scheme = r.baseURL.Scheme
+	req.URL.Host = r.baseURL.Host
+	// the base path is prepended as is: cleaning the result would drop trailing slashes and escaped segments of the recorded path.
+	if p := strings.TrimSuffix(r.baseURL.Path, "/"); p != "" {
+		if req.URL.RawPath != "" {
+			req.URL.RawPath = strings.TrimSuffix(r.baseURL.EscapedPath(), "/") + req.URL.RawPath
+		}
+		req.URL.Path = p + req.URL.Path
+	}
+
+	// the recorded host header refers to the original service.
+	req.Host = ""
+}
+
+func entryStartTime(e *har.Entry) time.Time {
+	if !e.StartDateTimeTm.IsZero() {
+		return e.StartDateTimeTm
+	}
+
+	tm, err := time.Parse(time.RFC3339Nano, e.StartedDateTime)
+	if err != nil {
+		return time.Time{}
+	}
+	return tm
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,107 @@
+package harreplay_test
+
+import (
+	"context"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/har"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/harreplay"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestToHttpRequest(t *testing.T) {
+	req := &har.Request{
+		Method:      http.MethodPost,
+		URL:         "http://prod.example.com/api/v1/login",
+		Headers:     har.NameValuePairs{{Name: "Host", Value: "prod.example.com"}, {Name: "Content-Length", Value: "99"}, {Name: "X-Request-Id", Value: "abc"}},
+		QueryString: har.NameValuePairs{{Name: "lang", Value: "it"}},
+		Cookies:     []har.Cookie{{Name: "session", Value: "s1"}},
+		PostData: &har.PostData{
+			MimeType: "application/x-www-form-urlencoded",
+			Params:   []har.Param{{Name: "user", Value: "mario"}, {Name: "pwd", Value: "rossi"}},
+		},
+	}
+
+	httpReq, err := req.ToHttpRequest(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "http://prod.example.com/api/v1/login?lang=it", httpReq.URL.String())
+	require.Equal(t, "prod.example.com", httpReq.Host)
+	require.Equal(t, "abc", httpReq.Header.Get("X-Request-Id"))
+	require.Empty(t, httpReq.Header.Get("Content-Length"))
+
+	c, err := httpReq.Cookie("session")
+	require.NoError(t, err)
+	require.Equal(t, "s1", c.Value)
+
+	b, err := io.ReadAll(httpReq.Body)
+	require.NoError(t, err)
+	require.Equal(t, "pwd=rossi&user=mario", string(b))
+}
+
+func TestReplay(t *testing.T) {
+
+	var received []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		received = append(received, r.Method+" "+r.URL.RequestURI()+" "+string(b))
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer srv.Close()
+
+	h := har.NewHAR(
+		har.WithEntry(&har.Entry{
+			StartedDateTime: "2023-02-12T20:07:02.100000+01:00",
+			Request:         &har.Request{Method: http.MethodGet, URL: "http://prod.example.com/api/v1/items?id=1"},
+		}),
+		har.WithEntry(&har.Entry{
+			StartedDateTime: "2023-02-12T20:07:02.300000+01:00",
+			Request: &har.Request{
+				Method:   http.MethodPost,
+				URL:      "http://prod.example.com/api/v1/items",
+				PostData: &har.PostData{MimeType: "application/json", Text: `{"id":2}`},
+			},
+			TraceId: "log:log:span:1",
+		}),
+	)
+
+	r, err := harreplay.NewReplayer(srv.URL+"/base", harreplay.WithPreserveTiming(true, 1), harreplay.WithHeader("Authorization", "Bearer local"))
+	require.NoError(t, err)
+
+	start := time.Now()
+	out, err := r.Replay(context.Background(), h)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, time.Since(start), 150*time.Millisecond)
+
+	require.Equal(t, []string{"GET /base/api/v1/items?id=1 ", `POST /base/api/v1/items {"id":2}`}, received)
+	require.Len(t, out.Log.Entries, 2)
+	require.Equal(t, http.StatusCreated, out.Log.Entries[1].Response.Status)
+	require.Equal(t, srv.URL+"/base/api/v1/items", out.Log.Entries[1].Request.URL)
+	require.Equal(t, "log:log:span:1", out.Log.Entries[1].TraceId)
+	require.Equal(t, "Bearer local", out.Log.Entries[1].Request.Headers.GetFirst("Authorization").Value)
+}
+
+func TestReplayKeepsRecordedPath(t *testing.T) {
+
+	var received []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.URL.EscapedPath())
+	}))
+	defer srv.Close()
+
+	h := har.NewHAR(
+		har.WithEntry(&har.Entry{Request: &har.Request{Method: http.MethodGet, URL: "http://prod.example.com/api/items/"}}),
+		har.WithEntry(&har.Entry{Request: &har.Request{Method: http.MethodGet, URL: "http://prod.example.com/api/files/a%2Fb"}}),
+	)
+
+	r, err := harreplay.NewReplayer(srv.URL + "/base/")
+	require.NoError(t, err)
+
+	_, err = r.Replay(context.Background(), h)
+	require.NoError(t, err)
+	require.Equal(t, []string{"/base/api/items/", "/base/api/files/a%2Fb"}, received)
+}
@@ -0,0 +1,324 @@
+package hartestgen
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/har"
+	"go/format"
+	"io"
+	"mime"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// VolatileValuePatterns match values that usually change at every execution: timestamps and uuids.
+// They can be passed to ExcludeValues.
+var VolatileValuePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`^\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}`),
+	regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`),
+}
+
+type generatorOpts struct {
+	pkg                     string
+	testName                string
+	imports                 []string
+	constructor             string
+	constructorReturnsError bool
+	source                  string
+	fields                  [][]string
+	excludedFields          [][]string
+	excludedValues          []*regexp.Regexp
+	headers                 []string
+}
+
+type Option func(o *generatorOpts)
+
+// WithPackage sets the package clause of the generated file. Defaults to main_test.
+func WithPackage(p string) Option {
+	return func(o *generatorOpts) {
+		o.pkg = p
+	}
+}
+
+// WithTestName sets the name of the generated test function. Defaults to TestHarRegression.
+func WithTestName(n string) Option {
+	return func(o *generatorOpts) {
+		o.testName = n
+	}
+}
+
+// WithImport adds an import to the generated file, alias may be empty.
+func WithImport(alias, path string) Option {
+	return func(o *generatorOpts) {
+		o.imports = append(o.imports, strings.TrimSpace(alias+" "+strconv.Quote(path)))
+	}
+}
+
+// WithHandlerConstructor sets the call expression building the http.Handler under test, e.g. api.NewHandler().
+// If returnsError the expression returns the handler and an error.
+func WithHandlerConstructor(expr string, returnsError bool) Option {
+	return func(o *generatorOpts) {
+		o.constructor = expr
+		o.constructorReturnsError = returnsError
+	}
+}
+
+// WithSource records the name of the har file in the header of the generated file.
+func WithSource(fn string) Option {
+	return func(o *generatorOpts) {
+		o.source = fn
+	}
+}
+
+// AssertFields restricts the assertions to the json fields matching the dot paths (* wildcard) or nested in them.
+// By default all the fields of the recorded json responses are asserted.
+func AssertFields(paths ...string) Option {
+	return func(o *generatorOpts) {
+		o.fields = append(o.fields, splitPaths(paths)...)
+	}
+}
+
+// ExcludeFields excludes the json fields matching the dot paths (* wildcard), and the ones nested in them, from the assertions.
+func ExcludeFields(paths ...string) Option {
+	return func(o *generatorOpts) {
+		o.excludedFields = append(o.excludedFields, splitPaths(paths)...)
+	}
+}
+
+// ExcludeValues excludes the fields whose recorded string value matches one of the expressions (see VolatileValuePatterns).
+func ExcludeValues(res ...*regexp.Regexp) Option {
+	return func(o *generatorOpts) {
+		o.excludedValues = append(o.excludedValues, res...)
+	}
+}
+
+// AssertHeaders adds assertions on the recorded values of the named response headers.
+func AssertHeaders(names ...string) Option {
+	return func(o *generatorOpts) {
+		o.headers = append(o.headers, names...)
+	}
+}
+
+func splitPaths(paths []string) [][]string {
+	var res [][]string
+	for _, p := range paths {
+		if p = strings.TrimSpace(p); p != "" {
+			res = append(res, strings.Split(p, "."))
+		}
+	}
+	return res
+}
+
+type testCase struct {
+	Name        string
+	Method      string
+	Target      string
+	Headers     [][2]string
+	Body        string
+	Status      int
+	RespHeaders [][2]string
+	Fields      [][2]string
+}
+
+type testFile struct {
+	Package                 string
+	TestName                string
+	Source                  string
+	Imports                 []string
+	Constructor             string
+	ConstructorReturnsError bool
+	Cases                   []testCase
+}
+
+// Generate writes on w a go test file replaying, through httptest, the requests of the har against the handler returned
+// by the constructor and asserting the recorded status codes, the selected response headers and the json fields not
+// excluded by the rules.
+func Generate(w io.Writer, h *har.HAR, opts ...Option) error {
+	o := generatorOpts{pkg: "main_test", testName: "TestHarRegression"}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.constructor == "" {
+		return fmt.Errorf("the handler constructor is required")
+	}
+
+	tf := testFile{
+		Package:                 o.pkg,
+		TestName:                o.testName,
+		Source:                  o.source,
+		Imports:                 o.imports,
+		Constructor:             o.constructor,
+		ConstructorReturnsError: o.constructorReturnsError,
+	}
+
+	if h != nil && h.Log != nil {
+		for i, e := range h.Log.Entries {
+			if e.Request == nil || e.Response == nil {
+				continue
+			}
+
+			tc, err := o.testCase(i, e)
+			if err != nil {
+				return err
+			}
+			tf.Cases = append(tf.Cases, tc)
+		}
+	}
+
+	tmpl, err := template.New("test").Funcs(template.FuncMap{"quote": strconv.Quote}).Parse(testFileTemplate)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err = tmpl.Execute(&buf, tf); err != nil {
+		return err
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(src)
+	return err
+}
+
+func (o *generatorOpts) testCase(ndx int, e *har.Entry) (testCase, error) {
+	// the http request takes care of the query string and of the bodies made of params.
+	req, err := e.Request.ToHttpRequest(context.Background())
+	if err != nil {
+		return testCase{}, err
+	}
+
+	tc := testCase{
+		Name:   fmt.Sprintf("%03d %s %s", ndx, req.Method, req.URL.Path),
+		Method: req.Method,
+		Target: req.URL.String(),
+		Status: e.Response.Status,
+	}
+
+	if req.Body != nil {
+		b, err := io.ReadAll(req.Body)
+		if err != nil {
+			return testCase{}, err
+		}
+		tc.Body = string(b)
+	}
+
+	names := make([]string, 0, len(req.Header))
+	for n := range req.Header {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		for _, v := range req.Header[n] {
+			tc.Headers = append(tc.Headers, [2]string{n, v})
+		}
+	}
+
+	if req.Host != "" && req.Host != req.URL.Host {
+		tc.Headers = append(tc.Headers, [2]string{"Host", req.Host})
+	}
+
+	for _, n := range o.headers {
+		for _, h := range e.Response.Headers {
+			if strings.EqualFold(h.Name, n) {
+				tc.RespHeaders = append(tc.RespHeaders, [2]string{h.Name, h.Value})
+				break
+			}
+		}
+	}
+
+	tc.Fields = o.fieldAssertions(e.Response.Content)
+	return tc, nil
+}
+
+func (o *generatorOpts) fieldAssertions(c *har.Content) [][2]string {
+	if c == nil {
+		return nil
+	}
+
+	mt, _, _ := mime.ParseMediaType(c.MimeType)
+	if mt != "application/json" && !strings.HasSuffix(mt, "+json") {
+		return nil
+	}
+
+	var v interface{}
+	if err := json.Unmarshal(c.Body(), &v); err != nil {
+		return nil
+	}
+
+	var fields [][2]string
+	o.collectFields(nil, v, &fields)
+	return fields
+}
+
+// collectFields visits the json document adding the leaves that are selected and not excluded.
+func (o *generatorOpts) collectFields(path []string, v interface{}, fields *[][2]string) {
+	if len(path) > 0 && matchesAny(o.excludedFields, path) {
+		return
+	}
+
+	switch tv := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(tv))
+		for k := range tv {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			o.collectFields(append(append([]string{}, path...), k), tv[k], fields)
+		}
+		return
+
+	case []interface{}:
+		for i, item := range tv {
+			o.collectFields(append(append([]string{}, path...), strconv.Itoa(i)), item, fields)
+		}
+		return
+
+	case string:
+		for _, re := range o.excludedValues {
+			if re.MatchString(tv) {
+				return
+			}
+		}
+	}
+
+	if len(path) == 0 || (len(o.fields) > 0 && !matchesAny(o.fields, path)) {
+		return
+	}
+
+	b, _ := json.Marshal(v)
+	*fields = append(*fields, [2]string{strings.Join(path, "."), string(b)})
+}
+
+// matchesAny tells if one of the patterns matches the path or one of its ancestors.
+func matchesAny(patterns [][]string, path []string) bool {
+	for _, p := range patterns {
+		if len(p) > len(path) {
+			continue
+		}
+
+		match := true
+		for i := range p {
+			if p[i] != "*" && p[i] != path[i] {
+				match = false
+				break
+			}
+		}
+
+		if match {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package hartestgen_test
+
+import (
+	"bytes"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/har"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/hartestgen"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	req1, err := har.NewRequest(http.MethodPost, "http://localhost:3004/api/v1/orders?lang=it", []byte(`{"canale":"APPP"}`), http.Header{"Content-Type": []string{"application/json"}}, nil, nil)
+	require.NoError(t, err)
+	req2, err := har.NewRequest(http.MethodGet, "http://localhost:3004/api/v1/orders/12", nil, http.Header{}, nil, nil)
+	require.NoError(t, err)
+
+	resp1 := har.NewResponse(201, "Created", "application/json", []byte(`{"id":"3f9c2a50-8f0e-4f6e-9d1a-2b8f1c0e9a11","esito":"OK","ts":12}`), nil)
+	resp1.Headers = har.NameValuePairs{{Name: "Location", Value: "/api/v1/orders/12"}}
+	h := har.NewHAR(
+		har.WithEntry(&har.Entry{Request: req1, Response: resp1}),
+		har.WithEntry(&har.Entry{Request: req2, Response: har.NewResponse(200, "OK", "application/json", []byte(`{"items":[{"sku":"a","qty":1}]}`), nil)}),
+	)
+
+	var buf bytes.Buffer
+	err = hartestgen.Generate(&buf, h,
+		hartestgen.WithPackage("api_test"),
+		hartestgen.WithImport("", "github.com/acme/orders/api"),
+		hartestgen.WithHandlerConstructor("api.NewHandler()", true),
+		hartestgen.ExcludeFields("ts"),
+		hartestgen.ExcludeValues(hartestgen.VolatileValuePatterns...),
+		hartestgen.AssertHeaders("location"),
+	)
+	require.NoError(t, err)
+
+	src := buf.String()
+	t.Log("\n" + src)
+	require.Contains(t, src, "package api_test")
+	require.Contains(t, src, `"github.com/acme/orders/api"`)
+	require.Contains(t, src, "handler, err := api.NewHandler()")
+	require.Contains(t, src, `{"esito", "\"OK\""}`)
+	require.Contains(t, src, `{"items.0.qty", "1"}`)
+	require.Contains(t, src, `{"Location", "/api/v1/orders/12"}`)
+	require.NotContains(t, src, `"ts"`)
+	require.NotContains(t, src, `3f9c2a50`)
+
+	buf.Reset()
+	require.NoError(t, hartestgen.Generate(&buf, h, hartestgen.WithHandlerConstructor("newHandler()", false), hartestgen.AssertFields("items.*.sku")))
+	require.Contains(t, buf.String(), `{"items.0.sku", "\"a\""}`)
+	require.NotContains(t, buf.String(), `"esito"`)
+
+	require.Error(t, hartestgen.Generate(&buf, h))
+}
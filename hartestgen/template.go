@@ -0,0 +1,130 @@
+package hartestgen
+
+const testFileTemplate = `// Code generated by har testgen{{ if .Source }} from {{ .Source }}{{ end }}. DO NOT EDIT.
+
+package {{ .Package }}
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+{{ range .Imports }}
+	{{ . }}{{ end }}
+)
+
+func {{ .TestName }}(t *testing.T) {
+{{- if .ConstructorReturnsError }}
+	handler, err := {{ .Constructor }}
+	if err != nil {
+		t.Fatal(err)
+	}
+{{- else }}
+	handler := {{ .Constructor }}
+{{- end }}
+
+	// lookup returns the value of a field of a json document given its dot path, array elements are addressed by index.
+	lookup := func(v interface{}, path string) (interface{}, bool) {
+		for _, k := range strings.Split(path, ".") {
+			switch tv := v.(type) {
+			case map[string]interface{}:
+				var ok bool
+				if v, ok = tv[k]; !ok {
+					return nil, false
+				}
+			case []interface{}:
+				i, err := strconv.Atoi(k)
+				if err != nil || i < 0 || i >= len(tv) {
+					return nil, false
+				}
+				v = tv[i]
+			default:
+				return nil, false
+			}
+		}
+		return v, true
+	}
+
+	tests := []struct {
+		name    string
+		method  string
+		target  string
+		headers [][2]string
+		body    string
+		status  int
+		respHeaders [][2]string
+		fields  [][2]string // dot path and json value
+	}{
+{{- range .Cases }}
+		{
+			name:   {{ quote .Name }},
+			method: {{ quote .Method }},
+			target: {{ quote .Target }},
+			{{- if .Headers }}
+			headers: [][2]string{ {{- range .Headers }}
+				{ {{ quote (index . 0) }}, {{ quote (index . 1) }} },{{ end }}
+			},
+			{{- end }}
+			{{- if .Body }}
+			body: {{ quote .Body }},
+			{{- end }}
+			status: {{ .Status }},
+			{{- if .RespHeaders }}
+			respHeaders: [][2]string{ {{- range .RespHeaders }}
+				{ {{ quote (index . 0) }}, {{ quote (index . 1) }} },{{ end }}
+			},
+			{{- end }}
+			{{- if .Fields }}
+			fields: [][2]string{ {{- range .Fields }}
+				{ {{ quote (index . 0) }}, {{ quote (index . 1) }} },{{ end }}
+			},
+			{{- end }}
+		},
+{{- end }}
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			for _, h := range tt.headers {
+				req.Header.Add(h[0], h[1])
+			}
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status: got %d, want %d", rec.Code, tt.status)
+			}
+
+			for _, h := range tt.respHeaders {
+				if got := rec.Header().Get(h[0]); got != h[1] {
+					t.Errorf("header %s: got %q, want %q", h[0], got, h[1])
+				}
+			}
+
+			if len(tt.fields) == 0 {
+				return
+			}
+
+			var body interface{}
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("response body is not json: %v", err)
+			}
+
+			for _, f := range tt.fields {
+				v, ok := lookup(body, f[0])
+				if !ok {
+					t.Errorf("field %s: not found", f[0])
+					continue
+				}
+
+				if got, _ := json.Marshal(v); string(got) != f[1] {
+					t.Errorf("field %s: got %s, want %s", f[0], got, f[1])
+				}
+			}
+		})
+	}
+}
+`
@@ -0,0 +1,77 @@
+package hartracing
+
+import (
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/har"
+	"log/slog"
+	"unicode/utf8"
+)
+
+const (
+	TraceIdLogAttrName    = "har-trace-id"
+	DefaultMaxLogBodySize = 4096
+	TruncatedBodySuffix   = "...[truncated]"
+)
+
+// EntryLogFormat describes how the log based tracers render an entry.
+type EntryLogFormat struct {
+	IncludeBodies bool // include the request and response bodies.
+	MaxBodySize   int  // bodies longer than this are truncated, DefaultMaxLogBodySize if <= 0.
+}
+
+// Attrs returns the fields describing the entry: trace id, start, duration in milliseconds, request and response summary and, optionally, the bodies.
+func (f EntryLogFormat) Attrs(e *har.Entry) []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String(TraceIdLogAttrName, e.TraceId),
+		slog.String("started", e.StartedDateTime),
+		slog.Float64("duration-ms", e.Time),
+	}
+
+	if e.Comment != "" {
+		attrs = append(attrs, slog.String("comment", e.Comment))
+	}
+
+	if e.Request != nil {
+		attrs = append(attrs,
+			slog.String("method", e.Request.Method),
+			slog.String("url", e.Request.URL),
+			slog.Int64("req-body-size", e.Request.BodySize),
+		)
+		if f.IncludeBodies && e.Request.PostData != nil {
+			attrs = append(attrs, slog.String("req-body", f.truncate(e.Request.PostData.Data, e.Request.PostData.Text)))
+		}
+	}
+
+	if e.Response != nil {
+		attrs = append(attrs,
+			slog.Int("status", e.Response.Status),
+			slog.Int64("resp-body-size", e.Response.BodySize),
+		)
+		if f.IncludeBodies && e.Response.Content != nil {
+			attrs = append(attrs, slog.String("resp-body", f.truncate(e.Response.Content.Data, e.Response.Content.Text)))
+		}
+	}
+
+	return attrs
+}
+
+func (f EntryLogFormat) truncate(data []byte, text string) string {
+	if len(data) > 0 {
+		text = string(data)
+	}
+
+	maxBodySize := f.MaxBodySize
+	if maxBodySize <= 0 {
+		maxBodySize = DefaultMaxLogBodySize
+	}
+
+	if len(text) > maxBodySize {
+		// the cut steps back to the start of a rune not to leave an invalid utf-8 sequence.
+		n := maxBodySize
+		for n > 0 && !utf8.RuneStart(text[n]) {
+			n--
+		}
+		return text[:n] + TruncatedBodySuffix
+	}
+
+	return text
+}
@@ -0,0 +1,24 @@
+package hartracing_test
+
+import (
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/har"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/hartracing"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestEntryLogTruncate(t *testing.T) {
+	f := hartracing.EntryLogFormat{IncludeBodies: true, MaxBodySize: 4}
+	e := &har.Entry{Response: &har.Response{Content: &har.Content{Text: "abcèf"}}}
+
+	var body string
+	for _, a := range f.Attrs(e) {
+		if a.Key == "resp-body" {
+			body = a.Value.String()
+		}
+	}
+
+	require.True(t, utf8.ValidString(body))
+	require.Equal(t, "abc"+hartracing.TruncatedBodySuffix, body)
+}
@@ -14,6 +14,11 @@ func (hs *spanImpl) Finish() error {
 	const semLogContext = "file-har-tracer::finish-span"
 
 	hs.Duration = time.Since(hs.StartTime)
+	if !hs.Sampled() {
+		log.Trace().Str("span-id", hs.Id()).Msg(semLogContext + " span not sampled....")
+		return nil
+	}
+
 	if len(hs.Entries) > 0 {
 		log.Trace().Str("span-id", hs.Id()).Msg(semLogContext + " reporting span")
 		_ = hs.Tracer.(*tracerImpl).Report(hs)
@@ -11,23 +11,38 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
-	"strings"
 	"time"
 )
 
 const (
 	TargetFolderEnvName = "HAR_FILE_TRACER_FOLDER"
 	HarFileTracerType   = "har-file-tracer"
+	DefaultQueueSize    = 10
+
+	FormatHAR      = "har"
+	FormatNDJSON   = "ndjson"
+	NDJSONFileName = "har-entries.ndjson"
 )
 
 type tracerImpl struct {
-	targetFolder string
-	done         bool
-	outCh        chan *har.HAR
+	targetFolder       string
+	format             string
+	version            string
+	done               chan struct{}
+	outCh              chan *har.HAR
+	sampler            hartracing.Sampler
+	masking            *hartracing.MaskingRules
+	propagationHeaders []string
 }
 
 type tracerOpts struct {
-	folder string
+	folder             string
+	format             string
+	version            string
+	queueSize          int
+	sampler            hartracing.Sampler
+	masking            *hartracing.MaskingRules
+	propagationHeaders []string
 }
 
 type Option func(opts *tracerOpts)
@@ -38,6 +53,47 @@ func WithFolder(t string) Option {
 	}
 }
 
+// WithFormat sets the output format: FormatHAR (default) writes a har file per log id, FormatNDJSON appends the entries,
+// one per line, to the NDJSONFileName file of the folder.
+func WithFormat(f string) Option {
+	return func(opts *tracerOpts) {
+		opts.format = f
+	}
+}
+
+// WithVersion sets the har version of the output: har.Version12 (default) or har.Version11.
+func WithVersion(v string) Option {
+	return func(opts *tracerOpts) {
+		opts.version = v
+	}
+}
+
+// WithQueueSize sets the capacity of the channel buffering the spans waiting to be written.
+func WithQueueSize(n int) Option {
+	return func(opts *tracerOpts) {
+		opts.queueSize = n
+	}
+}
+
+func WithSampler(s hartracing.Sampler) Option {
+	return func(opts *tracerOpts) {
+		opts.sampler = s
+	}
+}
+
+func WithMasking(m *hartracing.MaskingRules) Option {
+	return func(opts *tracerOpts) {
+		opts.masking = m
+	}
+}
+
+// WithPropagationHeaders sets the carrier keys used to extract and inject the span context.
+func WithPropagationHeaders(h ...string) Option {
+	return func(opts *tracerOpts) {
+		opts.propagationHeaders = h
+	}
+}
+
 func NewTracer(opts ...Option) (hartracing.Tracer, io.Closer, error) {
 
 	const semLogContext = "file-har-tracer::new"
@@ -63,8 +119,45 @@ func NewTracer(opts ...Option) (hartracing.Tracer, io.Closer, error) {
 		return nil, nil, err
 	}
 
-	t := &tracerImpl{targetFolder: trcOpts.folder, outCh: make(chan *har.HAR, 10)}
-	log.Info().Str("tracer-type", HarFileTracerType).Str("folder", trcOpts.folder).Msg(semLogContext + " har tracer initialized")
+	switch trcOpts.format {
+	case "":
+		trcOpts.format = FormatHAR
+	case FormatHAR, FormatNDJSON:
+	default:
+		err := fmt.Errorf("unsupported format %s", trcOpts.format)
+		log.Error().Err(err).Msg(semLogContext)
+		return nil, nil, err
+	}
+
+	switch trcOpts.version {
+	case "":
+		trcOpts.version = har.DefaultVersion
+	case har.Version11, har.Version12:
+	default:
+		err := fmt.Errorf("unsupported har version %s", trcOpts.version)
+		log.Error().Err(err).Msg(semLogContext)
+		return nil, nil, err
+	}
+
+	if trcOpts.queueSize <= 0 {
+		trcOpts.queueSize = DefaultQueueSize
+	}
+
+	if trcOpts.sampler == nil {
+		trcOpts.sampler = hartracing.AlwaysSample()
+	}
+
+	t := &tracerImpl{
+		targetFolder:       trcOpts.folder,
+		format:             trcOpts.format,
+		version:            trcOpts.version,
+		done:               make(chan struct{}),
+		outCh:              make(chan *har.HAR, trcOpts.queueSize),
+		sampler:            trcOpts.sampler,
+		masking:            trcOpts.masking,
+		propagationHeaders: trcOpts.propagationHeaders,
+	}
+	log.Info().Str("tracer-type", HarFileTracerType).Str("folder", trcOpts.folder).Str("format", trcOpts.format).Msg(semLogContext + " har tracer initialized")
 
 	go t.processLoop()
 	return t, t, nil
@@ -75,9 +168,7 @@ func (t *tracerImpl) Close() error {
 	const semLogContext = "file-har-tracer::close"
 
 	close(t.outCh)
-	for !t.done {
-		time.Sleep(1 * time.Second)
-	}
+	<-t.done
 
 	log.Info().Msg(semLogContext + " closed")
 	return nil
@@ -88,30 +179,18 @@ func (t *tracerImpl) IsNil() bool {
 }
 
 func (t *tracerImpl) StartSpan(opts ...hartracing.SpanOption) hartracing.Span {
-	const semLogContext = "file-har-tracer::start-har-span"
-
 	spanOpts := hartracing.SpanOptions{}
 	for _, o := range opts {
 		o(&spanOpts)
 	}
 
-	oid := util.NewTraceId()
-	spanCtx := hartracing.SimpleSpanContext{LogId: oid, ParentId: oid, TraceId: oid, Flag: hartracing.HARSpanFlagSampled}
-
-	if spanOpts.ParentContext != nil {
-		if ctxImpl, ok := spanOpts.ParentContext.(hartracing.SimpleSpanContext); ok {
-			spanCtx.LogId = ctxImpl.LogId
-			spanCtx.ParentId = ctxImpl.TraceId
-		} else {
-			log.Warn().Msg(semLogContext + " unsupported implementation: wanted internal.spanContextImpl")
-		}
-	}
-
 	span := spanImpl{
 		hartracing.SimpleSpan{
 			Tracer:      t,
-			SpanContext: spanCtx,
+			SpanContext: hartracing.NewSimpleSpanContext(spanOpts.ParentContext, t.sampler),
 			StartTime:   time.Now(),
+			Masking:     t.masking,
+			Version:     t.version,
 		},
 	}
 
@@ -137,6 +216,13 @@ func (t *tracerImpl) processLoop() error {
 	log.Info().Msg(semLogContext + " starting loop")
 
 	for h := range t.outCh {
+		if t.format == FormatNDJSON {
+			if err := t.appendLines(h); err != nil {
+				log.Error().Err(err).Msg(semLogContext)
+			}
+			continue
+		}
+
 		b, err := json.Marshal(h)
 		if err != nil {
 			log.Error().Err(err).Msg(semLogContext)
@@ -166,10 +252,23 @@ func (t *tracerImpl) processLoop() error {
 	}
 
 	log.Info().Msg(semLogContext + " ending loop")
-	t.done = true
+	close(t.done)
 	return nil
 }
 
+func (t *tracerImpl) appendLines(h *har.HAR) error {
+	f, err := os.OpenFile(filepath.Join(t.targetFolder, NDJSONFileName), os.O_CREATE|os.O_WRONLY|os.O_APPEND, fs.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	err = h.WriteNDJSON(f)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
 func (t *tracerImpl) merge(incoming *har.HAR, fileName string) (*har.HAR, error) {
 
 	const semLogContext = "file-har-tracer::merge"
@@ -220,26 +319,9 @@ func (t *tracerImpl) getFileName(traceId string) string {
 }
 
 func (t *tracerImpl) Extract(format string, tmr hartracing.TextMapReader) (hartracing.SpanContext, error) {
-
-	var spanContext hartracing.SimpleSpanContext
-	err := tmr.ForeachKey(func(key, val string) error {
-		var err error
-		if strings.ToLower(key) == hartracing.HARTraceIdHeaderName {
-			spanContext, err = hartracing.ExtractSimpleSpanContextFromString(val)
-			return err
-		}
-
-		return nil
-	})
-
-	if spanContext.IsZero() {
-		err = hartracing.ErrSpanContextNotFound
-	}
-
-	return spanContext, err
+	return hartracing.ExtractSimpleSpanContext(tmr, t.propagationHeaders...)
 }
 
 func (t *tracerImpl) Inject(s hartracing.SpanContext, tmr hartracing.TextMapWriter) error {
-	tmr.Set(hartracing.HARTraceIdHeaderName, s.Id())
-	return nil
+	return hartracing.InjectSimpleSpanContext(s, tmr, t.propagationHeaders...)
 }
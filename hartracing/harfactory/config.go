@@ -0,0 +1,63 @@
+package harfactory
+
+import (
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/hartracing"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/hartracing/filetracer"
+	"os"
+)
+
+// SamplingConfig drives the sampling of root spans. Child spans follow the decision propagated by their parent.
+type SamplingConfig struct {
+	Rate float64 `json:"rate,omitempty" yaml:"rate,omitempty" mapstructure:"rate,omitempty"` // Ratio (0 < rate < 1) of the root spans to be recorded. Zero or values >= 1 record every span.
+}
+
+// PropagationConfig lists the carrier keys used to propagate the span context. The har-trace-id key is always honored on extraction.
+type PropagationConfig struct {
+	Headers []string `json:"headers,omitempty" yaml:"headers,omitempty" mapstructure:"headers,omitempty"` // Header names written on inject and accepted on extract. Defaults to har-trace-id.
+}
+
+// LogZeroConfig drives the output of the har-logzero-tracer.
+type LogZeroConfig struct {
+	Level         string `json:"level,omitempty" yaml:"level,omitempty" mapstructure:"level,omitempty"`                            // Level of the events emitted for each entry. Defaults to info.
+	IncludeBodies bool   `json:"include-bodies,omitempty" yaml:"include-bodies,omitempty" mapstructure:"include-bodies,omitempty"` // Adds request and response bodies to the events.
+	MaxBodySize   int    `json:"max-body-size,omitempty" yaml:"max-body-size,omitempty" mapstructure:"max-body-size,omitempty"`    // Bodies longer than this are truncated. Defaults to hartracing.DefaultMaxLogBodySize.
+}
+
+// SlogConfig drives the output of the har-slog-tracer.
+type SlogConfig struct {
+	Level         string `json:"level,omitempty" yaml:"level,omitempty" mapstructure:"level,omitempty"`                            // Level of the records emitted for each entry (debug, info, warn, error). Defaults to info.
+	IncludeBodies bool   `json:"include-bodies,omitempty" yaml:"include-bodies,omitempty" mapstructure:"include-bodies,omitempty"` // Adds request and response bodies to the records.
+	MaxBodySize   int    `json:"max-body-size,omitempty" yaml:"max-body-size,omitempty" mapstructure:"max-body-size,omitempty"`    // Bodies longer than this are truncated. Defaults to hartracing.DefaultMaxLogBodySize.
+}
+
+// Config is the structured configuration of the har tracing. The HAR_TRACER_TYPE and HAR_FILE_TRACER_FOLDER env vars, if set,
+// override the corresponding values.
+type Config struct {
+	TracerType  string                   `json:"type,omitempty" yaml:"type,omitempty" mapstructure:"type,omitempty"`                      // One of har-file-tracer, har-logzero-tracer, har-slog-tracer. Empty means tracing disabled.
+	Folder      string                   `json:"folder,omitempty" yaml:"folder,omitempty" mapstructure:"folder,omitempty"`                // Target folder of the har-file-tracer.
+	Format      string                   `json:"format,omitempty" yaml:"format,omitempty" mapstructure:"format,omitempty"`                // Output format of the har-file-tracer: har (default) or ndjson.
+	Version     string                   `json:"version,omitempty" yaml:"version,omitempty" mapstructure:"version,omitempty"`             // Har version written by the har-file-tracer: 1.2 (default) or 1.1.
+	QueueSize   int                      `json:"queue-size,omitempty" yaml:"queue-size,omitempty" mapstructure:"queue-size,omitempty"`    // Number of spans buffered before the writer is reached. Defaults to filetracer.DefaultQueueSize.
+	Sampling    SamplingConfig           `json:"sampling,omitempty" yaml:"sampling,omitempty" mapstructure:"sampling,omitempty"`          // Sampling of the spans.
+	Masking     *hartracing.MaskingRules `json:"masking,omitempty" yaml:"masking,omitempty" mapstructure:"masking,omitempty"`             // Headers, query params and cookies obfuscated before recording.
+	Propagation PropagationConfig        `json:"propagation,omitempty" yaml:"propagation,omitempty" mapstructure:"propagation,omitempty"` // Propagation of the span context over the wire.
+	LogZero     LogZeroConfig            `json:"logzero,omitempty" yaml:"logzero,omitempty" mapstructure:"logzero,omitempty"`             // Output of the har-logzero-tracer.
+	Slog        SlogConfig               `json:"slog,omitempty" yaml:"slog,omitempty" mapstructure:"slog,omitempty"`                      // Output of the har-slog-tracer.
+}
+
+// WithEnvOverrides returns a copy of the config with the values found in the environment.
+func (cfg Config) WithEnvOverrides() Config {
+	if v := os.Getenv(hartracing.HARTracerTypeEnvName); v != "" {
+		cfg.TracerType = v
+	}
+
+	if v := os.Getenv(filetracer.TargetFolderEnvName); v != "" {
+		cfg.Folder = v
+	}
+
+	return cfg
+}
+
+func (cfg Config) sampler() hartracing.Sampler {
+	return hartracing.RateSampler(cfg.Sampling.Rate)
+}
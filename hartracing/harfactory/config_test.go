@@ -0,0 +1,112 @@
+package harfactory_test
+
+import (
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/har"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/hartracing"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/hartracing/filetracer"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/hartracing/harfactory"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/hartracing/logzerotracer"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConfigEnvOverrides(t *testing.T) {
+	t.Setenv(hartracing.HARTracerTypeEnvName, filetracer.HarFileTracerType)
+	t.Setenv(filetracer.TargetFolderEnvName, "/tmp/from-env")
+
+	cfg := harfactory.Config{TracerType: logzerotracer.HarLogZeroTracerType, Folder: "/tmp/from-cfg"}.WithEnvOverrides()
+	require.Equal(t, filetracer.HarFileTracerType, cfg.TracerType)
+	require.Equal(t, "/tmp/from-env", cfg.Folder)
+}
+
+func TestInitHarTracing(t *testing.T) {
+	t.Setenv(hartracing.HARTracerTypeEnvName, "")
+
+	cfg := harfactory.Config{
+		TracerType:  logzerotracer.HarLogZeroTracerType,
+		Masking:     &hartracing.MaskingRules{Headers: []string{"Authorization"}, QueryParams: []string{"token"}},
+		Propagation: harfactory.PropagationConfig{Headers: []string{"x-har-trace-id"}},
+	}
+
+	c, err := harfactory.InitHarTracing(cfg)
+	require.NoError(t, err)
+	defer c.Close()
+
+	trc := hartracing.GlobalTracer()
+	require.False(t, trc.IsNil())
+
+	s := trc.StartSpan()
+	headers := http.Header{}
+	require.NoError(t, trc.Inject(s.Context(), hartracing.HTTPHeadersCarrier(headers)))
+	require.Equal(t, s.Id(), headers.Get("x-har-trace-id"))
+
+	sctx, err := trc.Extract("", hartracing.HTTPHeadersCarrier(headers))
+	require.NoError(t, err)
+	require.Equal(t, s.Id(), sctx.Id())
+
+	e := &har.Entry{
+		Request: &har.Request{
+			URL:         "http://localhost/api?token=secret&id=1",
+			Headers:     har.NameValuePairs{{Name: "authorization", Value: "Bearer abc"}},
+			QueryString: har.NameValuePairs{{Name: "token", Value: "secret"}, {Name: "id", Value: "1"}},
+		},
+	}
+	require.NoError(t, s.AddEntry(e))
+	require.Equal(t, hartracing.DefaultMaskReplacement, e.Request.Headers[0].Value)
+	require.Equal(t, hartracing.DefaultMaskReplacement, e.Request.QueryString[0].Value)
+	require.Equal(t, "1", e.Request.QueryString[1].Value)
+	require.NotContains(t, e.Request.URL, "secret")
+}
+
+func TestInitHarTracingNDJSON(t *testing.T) {
+	t.Setenv(hartracing.HARTracerTypeEnvName, "")
+	t.Setenv(filetracer.TargetFolderEnvName, "")
+
+	folder := t.TempDir()
+	c, err := harfactory.InitHarTracing(harfactory.Config{TracerType: filetracer.HarFileTracerType, Folder: folder, Format: filetracer.FormatNDJSON})
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		s := hartracing.GlobalTracer().StartSpan()
+		require.NoError(t, s.AddEntry(&har.Entry{StartedDateTime: "2023-02-12T20:07:02.147874+01:00", Request: &har.Request{Method: http.MethodGet, URL: "http://localhost/api"}}))
+		require.NoError(t, s.Finish())
+	}
+	require.NoError(t, c.Close())
+
+	f, err := os.Open(filepath.Join(folder, filetracer.NDJSONFileName))
+	require.NoError(t, err)
+	defer f.Close()
+
+	h, err := har.ReadNDJSON(f)
+	require.NoError(t, err)
+	require.Len(t, h.Log.Entries, 2)
+	require.NotEqual(t, h.Log.Entries[0].TraceId, h.Log.Entries[1].TraceId)
+}
+
+func TestInitHarTracingVersion(t *testing.T) {
+	t.Setenv(hartracing.HARTracerTypeEnvName, "")
+	t.Setenv(filetracer.TargetFolderEnvName, "")
+
+	_, err := harfactory.InitHarTracing(harfactory.Config{TracerType: filetracer.HarFileTracerType, Folder: t.TempDir(), Version: "0.9"})
+	require.Error(t, err)
+
+	folder := t.TempDir()
+	c, err := harfactory.InitHarTracing(harfactory.Config{TracerType: filetracer.HarFileTracerType, Folder: folder, Version: har.Version11})
+	require.NoError(t, err)
+
+	s := hartracing.GlobalTracer().StartSpan()
+	require.NoError(t, s.AddEntry(&har.Entry{StartedDateTime: "2023-02-12T20:07:02.147874+01:00", Request: &har.Request{Method: http.MethodGet, URL: "http://localhost/api"}}))
+	require.NoError(t, s.Finish())
+	require.NoError(t, c.Close())
+
+	files, err := filepath.Glob(filepath.Join(folder, "*.har"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	b, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	require.Contains(t, string(b), `"version":"1.1"`)
+}
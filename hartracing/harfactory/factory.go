@@ -4,8 +4,11 @@ import (
 	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/hartracing"
 	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/hartracing/filetracer"
 	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/hartracing/logzerotracer"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/hartracing/slogtracer"
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"io"
+	"log/slog"
 	"os"
 	"strings"
 )
@@ -27,33 +30,84 @@ func IsHarTracerTypeFromEnvSupported() bool {
 		log.Info().Msgf(semLogContext+" env var %s not set", hartracing.HARTracerTypeEnvName)
 	}
 
-	return trcType == filetracer.HarFileTracerType || trcType == logzerotracer.HarLogZeroTracerType
+	return trcType == filetracer.HarFileTracerType || trcType == logzerotracer.HarLogZeroTracerType || trcType == slogtracer.HarSlogTracerType
 }
 
 func InitHarTracingFromEnv() (io.Closer, error) {
+	return InitHarTracing(Config{})
+}
+
+// InitHarTracing creates the tracer described by the config, env vars taking precedence, and sets it as the global tracer.
+func InitHarTracing(cfg Config) (io.Closer, error) {
 
-	const semLogContext = "har-tracing::init-from-env"
+	const semLogContext = "har-tracing::init"
 	const semLogLabelTracerType = "tracer-type"
 
 	var trc hartracing.Tracer
 	var closer io.Closer
 	var err error
 
-	trcType := HarTracerTypeFromEnv()
+	cfg = cfg.WithEnvOverrides()
+	trcType := strings.ToLower(cfg.TracerType)
 	if trcType == "" {
+		log.Info().Msg(semLogContext + " tracer type not set")
 		return closer, nil
 	}
 
 	log.Info().Str(semLogLabelTracerType, trcType).Msg(semLogContext)
-	switch strings.ToLower(trcType) {
+	switch trcType {
 	case filetracer.HarFileTracerType:
-		trc, closer, err = filetracer.NewTracer()
+		trc, closer, err = filetracer.NewTracer(
+			filetracer.WithFolder(cfg.Folder),
+			filetracer.WithFormat(cfg.Format),
+			filetracer.WithVersion(cfg.Version),
+			filetracer.WithQueueSize(cfg.QueueSize),
+			filetracer.WithSampler(cfg.sampler()),
+			filetracer.WithMasking(cfg.Masking),
+			filetracer.WithPropagationHeaders(cfg.Propagation.Headers...),
+		)
 		if err != nil {
 			return nil, err
 		}
 
 	case logzerotracer.HarLogZeroTracerType:
-		trc, closer, err = logzerotracer.NewTracer()
+		lvl := zerolog.InfoLevel
+		if cfg.LogZero.Level != "" {
+			lvl, err = zerolog.ParseLevel(cfg.LogZero.Level)
+			if err != nil {
+				log.Error().Err(err).Str("level", cfg.LogZero.Level).Msg(semLogContext)
+				return nil, err
+			}
+		}
+
+		trc, closer, err = logzerotracer.NewTracer(
+			logzerotracer.WithSampler(cfg.sampler()),
+			logzerotracer.WithMasking(cfg.Masking),
+			logzerotracer.WithPropagationHeaders(cfg.Propagation.Headers...),
+			logzerotracer.WithLevel(lvl),
+			logzerotracer.WithBodies(cfg.LogZero.IncludeBodies, cfg.LogZero.MaxBodySize),
+		)
+		if err != nil {
+			return nil, err
+		}
+
+	case slogtracer.HarSlogTracerType:
+		lvl := slog.LevelInfo
+		if cfg.Slog.Level != "" {
+			err = lvl.UnmarshalText([]byte(cfg.Slog.Level))
+			if err != nil {
+				log.Error().Err(err).Str("level", cfg.Slog.Level).Msg(semLogContext)
+				return nil, err
+			}
+		}
+
+		trc, closer, err = slogtracer.NewTracer(
+			slogtracer.WithSampler(cfg.sampler()),
+			slogtracer.WithMasking(cfg.Masking),
+			slogtracer.WithPropagationHeaders(cfg.Propagation.Headers...),
+			slogtracer.WithLevel(lvl),
+			slogtracer.WithBodies(cfg.Slog.IncludeBodies, cfg.Slog.MaxBodySize),
+		)
 		if err != nil {
 			return nil, err
 		}
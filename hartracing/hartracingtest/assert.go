@@ -0,0 +1,116 @@
+package hartracingtest
+
+import (
+	"fmt"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/har"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/hartracing"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// EntryMatch describes the expected entry. Zero values are not checked. URL matches either the full url or its path.
+type EntryMatch struct {
+	Method string
+	URL    string
+	Status int
+}
+
+func (m EntryMatch) Matches(e *har.Entry) bool {
+	if e == nil || e.Request == nil {
+		return false
+	}
+
+	if m.Method != "" && !strings.EqualFold(m.Method, e.Request.Method) {
+		return false
+	}
+
+	if m.URL != "" && m.URL != e.Request.URL {
+		u, err := url.Parse(e.Request.URL)
+		if err != nil || u.Path != m.URL {
+			return false
+		}
+	}
+
+	if m.Status != 0 && (e.Response == nil || e.Response.Status != m.Status) {
+		return false
+	}
+
+	return true
+}
+
+func (m EntryMatch) String() string {
+	return fmt.Sprintf("%s %s -> %d", m.Method, m.URL, m.Status)
+}
+
+// ExpectEntries fails the test if the number of recorded entries is not n.
+func (t *Tracer) ExpectEntries(tb testing.TB, n int) []*har.Entry {
+	tb.Helper()
+
+	entries := t.Entries()
+	if len(entries) != n {
+		tb.Errorf("expected %d har entries, found %d%s", n, len(entries), describeEntries(entries))
+	}
+	return entries
+}
+
+// ExpectEntry fails the test if no recorded entry matches the provided method, url and status. The first matching entry is returned.
+func (t *Tracer) ExpectEntry(tb testing.TB, method string, u string, status int) *har.Entry {
+	tb.Helper()
+	return t.ExpectEntryMatching(tb, EntryMatch{Method: method, URL: u, Status: status})
+}
+
+func (t *Tracer) ExpectEntryMatching(tb testing.TB, m EntryMatch) *har.Entry {
+	tb.Helper()
+
+	entries := t.Entries()
+	for _, e := range entries {
+		if m.Matches(e) {
+			return e
+		}
+	}
+
+	tb.Errorf("expected a har entry matching %s%s", m, describeEntries(entries))
+	return nil
+}
+
+// ExpectTraceIdPropagated fails the test if the headers don't carry a span context belonging to the same trace of the span.
+func ExpectTraceIdPropagated(tb testing.TB, span hartracing.Span, headers http.Header) {
+	tb.Helper()
+
+	if span == nil {
+		tb.Errorf("expected a span, got nil")
+		return
+	}
+
+	sctx, err := hartracing.ExtractSimpleSpanContext(hartracing.HTTPHeadersCarrier(headers))
+	if err != nil {
+		tb.Errorf("expected header %s to carry the span context: %v", hartracing.HARTraceIdHeaderName, err)
+		return
+	}
+
+	spanCtx, ok := span.Context().(hartracing.SimpleSpanContext)
+	if !ok {
+		tb.Errorf("unsupported span context %T", span.Context())
+		return
+	}
+
+	if sctx.LogId != spanCtx.LogId {
+		tb.Errorf("expected propagated log id %s, found %s", spanCtx.LogId, sctx.LogId)
+	}
+}
+
+func describeEntries(entries []*har.Entry) string {
+	var sb strings.Builder
+	for i, e := range entries {
+		sb.WriteString(fmt.Sprintf("\n  #%d ", i))
+		if e.Request != nil {
+			sb.WriteString(e.Request.Method + " " + e.Request.URL)
+		}
+		if e.Response != nil {
+			sb.WriteString(fmt.Sprintf(" -> %d", e.Response.Status))
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,143 @@
+package hartracingtest
+
+import (
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/har"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/hartracing"
+	"sync"
+	"time"
+)
+
+// Tracer is an in-memory hartracing.Tracer keeping the finished spans to be inspected by tests.
+type Tracer struct {
+	mu      sync.Mutex
+	sampler hartracing.Sampler
+	spans   []*Span
+}
+
+type Span struct {
+	hartracing.SimpleSpan
+}
+
+func (hs *Span) Finish() error {
+	hs.Duration = time.Since(hs.StartTime)
+	hs.Finished = true
+	hs.Tracer.(*Tracer).record(hs)
+	return nil
+}
+
+// LogId returns the id shared by all the spans of the same trace.
+func (hs *Span) LogId() string {
+	return hs.SpanContext.LogId
+}
+
+type Option func(t *Tracer)
+
+func WithSampler(s hartracing.Sampler) Option {
+	return func(t *Tracer) {
+		t.sampler = s
+	}
+}
+
+func NewTracer(opts ...Option) *Tracer {
+	t := &Tracer{sampler: hartracing.AlwaysSample()}
+	for _, o := range opts {
+		o(t)
+	}
+	return t
+}
+
+// Install sets the tracer as the global one and returns a func restoring the previous global tracer.
+func (t *Tracer) Install() func() {
+	prev := hartracing.GlobalTracer()
+	hartracing.SetGlobalTracer(t)
+	return func() {
+		hartracing.SetGlobalTracer(prev)
+	}
+}
+
+func (t *Tracer) Close() error {
+	return nil
+}
+
+func (t *Tracer) IsNil() bool {
+	return false
+}
+
+func (t *Tracer) StartSpan(opts ...hartracing.SpanOption) hartracing.Span {
+	spanOpts := hartracing.SpanOptions{}
+	for _, o := range opts {
+		o(&spanOpts)
+	}
+
+	return &Span{
+		hartracing.SimpleSpan{
+			Tracer:      t,
+			SpanContext: hartracing.NewSimpleSpanContext(spanOpts.ParentContext, t.sampler),
+			Creator:     spanOpts.Creator,
+			Browser:     spanOpts.Browser,
+			Comment:     spanOpts.Comment,
+			StartTime:   time.Now(),
+		},
+	}
+}
+
+func (t *Tracer) Extract(format string, tmr hartracing.TextMapReader) (hartracing.SpanContext, error) {
+	return hartracing.ExtractSimpleSpanContext(tmr)
+}
+
+func (t *Tracer) Inject(s hartracing.SpanContext, tmr hartracing.TextMapWriter) error {
+	return hartracing.InjectSimpleSpanContext(s, tmr)
+}
+
+func (t *Tracer) record(s *Span) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.spans = append(t.spans, s)
+}
+
+// Reset discards the finished spans.
+func (t *Tracer) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.spans = nil
+}
+
+// FinishedSpans returns the finished spans in order of completion.
+func (t *Tracer) FinishedSpans() []*Span {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]*Span(nil), t.spans...)
+}
+
+// SpansByLogId returns the finished spans belonging to the trace identified by logId.
+func (t *Tracer) SpansByLogId(logId string) []*Span {
+	var res []*Span
+	for _, s := range t.FinishedSpans() {
+		if s.LogId() == logId {
+			res = append(res, s)
+		}
+	}
+	return res
+}
+
+// Entries returns the entries of the finished and sampled spans.
+func (t *Tracer) Entries() []*har.Entry {
+	var res []*har.Entry
+	for _, s := range t.FinishedSpans() {
+		if s.Sampled() {
+			res = append(res, s.Entries...)
+		}
+	}
+	return res
+}
+
+// EntriesByLogId returns the entries of the finished and sampled spans belonging to the trace identified by logId.
+func (t *Tracer) EntriesByLogId(logId string) []*har.Entry {
+	var res []*har.Entry
+	for _, s := range t.SpansByLogId(logId) {
+		if s.Sampled() {
+			res = append(res, s.Entries...)
+		}
+	}
+	return res
+}
@@ -0,0 +1,40 @@
+package hartracingtest_test
+
+import (
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/har"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/hartracing"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/hartracing/hartracingtest"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+)
+
+func TestRecordingTracer(t *testing.T) {
+
+	tracer := hartracingtest.NewTracer()
+	restore := tracer.Install()
+	defer restore()
+
+	s := hartracing.GlobalTracer().StartSpan()
+	req, err := har.NewRequest(http.MethodGet, "http://localhost:3004/example-01/api/v1/endpoint-01", nil, http.Header{}, nil, nil)
+	require.NoError(t, err)
+	require.NoError(t, s.AddEntry(&har.Entry{Request: req, Response: har.NewResponse(200, "OK", "application/json", nil, nil)}))
+
+	outbound := http.Header{}
+	require.NoError(t, hartracing.GlobalTracer().Inject(s.Context(), hartracing.HTTPHeadersCarrier(outbound)))
+
+	child := hartracing.GlobalTracer().StartSpan(hartracing.ChildOf(s.Context()))
+	require.NoError(t, child.Finish())
+	require.NoError(t, s.Finish())
+
+	tracer.ExpectEntries(t, 1)
+	tracer.ExpectEntry(t, http.MethodGet, "/example-01/api/v1/endpoint-01", 200)
+	hartracingtest.ExpectTraceIdPropagated(t, s, outbound)
+
+	logId := s.Context().(hartracing.SimpleSpanContext).LogId
+	require.Len(t, tracer.SpansByLogId(logId), 2)
+	require.Len(t, tracer.EntriesByLogId(logId), 1)
+
+	tracer.Reset()
+	require.Empty(t, tracer.FinishedSpans())
+}
@@ -0,0 +1,58 @@
+package logzerotracer
+
+import (
+	"context"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/hartracing"
+	"github.com/rs/zerolog"
+)
+
+const (
+	TraceIdFieldName = "har-trace-id"
+	LogIdFieldName   = "har-log-id"
+	SampledFieldName = "har-sampled"
+)
+
+// LoggerFromContext returns a child of the provided logger carrying the fields of the span found in the context
+// (see hartracing.SpanFromContext). If the context doesn't carry a span the logger is returned as is.
+func LoggerFromContext(ctx context.Context, lg zerolog.Logger) zerolog.Logger {
+	span := spanFromContext(ctx)
+	if span == nil {
+		return lg
+	}
+
+	lgCtx := lg.With().Str(TraceIdFieldName, span.Id())
+	if sctx, ok := span.Context().(hartracing.SimpleSpanContext); ok {
+		lgCtx = lgCtx.Str(LogIdFieldName, sctx.LogId)
+	}
+
+	return lgCtx.Bool(SampledFieldName, span.Sampled()).Logger()
+}
+
+// ContextHook is a zerolog.Hook adding the span fields to the events whose context, set with Event.Ctx or
+// Logger.With().Ctx, carries a span.
+//
+//	lg := log.Logger.Hook(logzerotracer.ContextHook{})
+//	lg.Info().Ctx(ctx).Msg("...")
+type ContextHook struct {
+}
+
+func (h ContextHook) Run(e *zerolog.Event, level zerolog.Level, msg string) {
+	span := spanFromContext(e.GetCtx())
+	if span == nil {
+		return
+	}
+
+	e.Str(TraceIdFieldName, span.Id())
+	if sctx, ok := span.Context().(hartracing.SimpleSpanContext); ok {
+		e.Str(LogIdFieldName, sctx.LogId)
+	}
+	e.Bool(SampledFieldName, span.Sampled())
+}
+
+func spanFromContext(ctx context.Context) hartracing.Span {
+	if ctx == nil {
+		return nil
+	}
+
+	return hartracing.SpanFromContext(ctx)
+}
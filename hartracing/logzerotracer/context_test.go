@@ -0,0 +1,50 @@
+package logzerotracer_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/hartracing"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/hartracing/logzerotracer"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestLoggerFromContext(t *testing.T) {
+
+	tracer, _, _ := logzerotracer.NewTracer()
+	s := tracer.StartSpan()
+	ctx := hartracing.ContextWithSpan(context.Background(), s)
+
+	var buf bytes.Buffer
+	lg := logzerotracer.LoggerFromContext(ctx, zerolog.New(&buf))
+	lg.Info().Msg("with span")
+
+	var evt map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &evt))
+	require.Equal(t, s.Id(), evt[logzerotracer.TraceIdFieldName])
+	require.Equal(t, s.Context().(hartracing.SimpleSpanContext).LogId, evt[logzerotracer.LogIdFieldName])
+	require.Equal(t, true, evt[logzerotracer.SampledFieldName])
+}
+
+func TestContextHook(t *testing.T) {
+
+	tracer, _, _ := logzerotracer.NewTracer()
+	s := tracer.StartSpan()
+	ctx := hartracing.ContextWithSpan(context.Background(), s)
+
+	var buf bytes.Buffer
+	lg := zerolog.New(&buf).Hook(logzerotracer.ContextHook{})
+
+	lg.Info().Ctx(ctx).Msg("with span")
+	var evt map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &evt))
+	require.Equal(t, s.Id(), evt[logzerotracer.TraceIdFieldName])
+
+	buf.Reset()
+	lg.Info().Msg("without span")
+	evt = nil
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &evt))
+	require.NotContains(t, evt, logzerotracer.TraceIdFieldName)
+}
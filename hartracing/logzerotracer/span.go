@@ -15,6 +15,11 @@ func (hs *logZeroSpanImpl) Finish() error {
 	const semLogContext = "log-zero-har-tracer::finish-span"
 
 	hs.Duration = time.Since(hs.StartTime)
+	if !hs.Sampled() {
+		log.Trace().Str("span-id", hs.Id()).Msg(semLogContext + " span not sampled....")
+		return nil
+	}
+
 	if len(hs.Entries) > 0 {
 		log.Trace().Str("span-id", hs.Id()).Msg(semLogContext + " reporting span")
 		_ = hs.Tracer.(*logZeroTracerImpl).Report(hs)
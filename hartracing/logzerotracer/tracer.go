@@ -1,25 +1,108 @@
 package logzerotracer
 
 import (
-	"encoding/json"
-	"fmt"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/har"
 	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/hartracing"
-	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/hartracing/util"
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"io"
-	"strings"
+	"log/slog"
 	"time"
 )
 
 const (
 	HarLogZeroTracerType = "har-logzero-tracer"
+	DefaultMaxBodySize   = hartracing.DefaultMaxLogBodySize
+	TruncatedBodySuffix  = hartracing.TruncatedBodySuffix
 )
 
 type logZeroTracerImpl struct {
+	sampler            hartracing.Sampler
+	masking            *hartracing.MaskingRules
+	propagationHeaders []string
+	level              zerolog.Level
+	logger             *zerolog.Logger
+	format             hartracing.EntryLogFormat
 }
 
-func NewTracer() (hartracing.Tracer, io.Closer, error) {
-	t := &logZeroTracerImpl{}
+type tracerOpts struct {
+	sampler            hartracing.Sampler
+	masking            *hartracing.MaskingRules
+	propagationHeaders []string
+	level              zerolog.Level
+	logger             *zerolog.Logger
+	format             hartracing.EntryLogFormat
+}
+
+type Option func(opts *tracerOpts)
+
+// WithLevel sets the level of the events emitted for each entry. Defaults to info.
+func WithLevel(l zerolog.Level) Option {
+	return func(opts *tracerOpts) {
+		opts.level = l
+	}
+}
+
+// WithLogger sets the logger used to emit the entries. Defaults to the zerolog global logger.
+func WithLogger(l zerolog.Logger) Option {
+	return func(opts *tracerOpts) {
+		opts.logger = &l
+	}
+}
+
+// WithWriter emits the entries as json lines on the provided writer.
+func WithWriter(w io.Writer) Option {
+	return func(opts *tracerOpts) {
+		l := zerolog.New(w).With().Timestamp().Logger()
+		opts.logger = &l
+	}
+}
+
+// WithBodies includes the request and response bodies in the events, truncated to maxBodySize bytes (DefaultMaxBodySize if <= 0).
+func WithBodies(include bool, maxBodySize int) Option {
+	return func(opts *tracerOpts) {
+		opts.format = hartracing.EntryLogFormat{IncludeBodies: include, MaxBodySize: maxBodySize}
+	}
+}
+
+func WithSampler(s hartracing.Sampler) Option {
+	return func(opts *tracerOpts) {
+		opts.sampler = s
+	}
+}
+
+func WithMasking(m *hartracing.MaskingRules) Option {
+	return func(opts *tracerOpts) {
+		opts.masking = m
+	}
+}
+
+// WithPropagationHeaders sets the carrier keys used to extract and inject the span context.
+func WithPropagationHeaders(h ...string) Option {
+	return func(opts *tracerOpts) {
+		opts.propagationHeaders = h
+	}
+}
+
+func NewTracer(opts ...Option) (hartracing.Tracer, io.Closer, error) {
+
+	trcOpts := tracerOpts{level: zerolog.InfoLevel}
+	for _, o := range opts {
+		o(&trcOpts)
+	}
+
+	if trcOpts.sampler == nil {
+		trcOpts.sampler = hartracing.AlwaysSample()
+	}
+
+	t := &logZeroTracerImpl{
+		sampler:            trcOpts.sampler,
+		masking:            trcOpts.masking,
+		propagationHeaders: trcOpts.propagationHeaders,
+		level:              trcOpts.level,
+		logger:             trcOpts.logger,
+		format:             trcOpts.format,
+	}
 	return t, t, nil
 }
 
@@ -32,30 +115,17 @@ func (t *logZeroTracerImpl) IsNil() bool {
 }
 
 func (t *logZeroTracerImpl) StartSpan(opts ...hartracing.SpanOption) hartracing.Span {
-	const semLogContext = "log-zero-har-tracer::start-har-span"
-
 	spanOpts := hartracing.SpanOptions{}
 	for _, o := range opts {
 		o(&spanOpts)
 	}
 
-	oid := util.NewTraceId()
-	spanCtx := hartracing.SimpleSpanContext{LogId: oid, ParentId: oid, TraceId: oid, Flag: hartracing.HARSpanFlagSampled}
-
-	if spanOpts.ParentContext != nil {
-		if ctxImpl, ok := spanOpts.ParentContext.(hartracing.SimpleSpanContext); ok {
-			spanCtx.LogId = ctxImpl.LogId
-			spanCtx.ParentId = ctxImpl.TraceId
-		} else {
-			log.Warn().Msg(semLogContext + " unsupported implementation: wanted internal.spanContextImpl")
-		}
-	}
-
 	span := logZeroSpanImpl{
 		hartracing.SimpleSpan{
 			Tracer:      t,
-			SpanContext: spanCtx,
+			SpanContext: hartracing.NewSimpleSpanContext(spanOpts.ParentContext, t.sampler),
 			StartTime:   time.Now(),
+			Masking:     t.masking,
 		},
 	}
 
@@ -71,38 +141,46 @@ func (t *logZeroTracerImpl) Report(s *logZeroSpanImpl) error {
 		return err
 	}
 
-	b, err := json.Marshal(h)
-	if err != nil {
-		log.Error().Err(err).Msg(semLogContext)
-		return err
+	lg := t.logger
+	if lg == nil {
+		lg = &log.Logger
+	}
+
+	for _, e := range h.Log.Entries {
+		t.logEntry(lg, e)
 	}
 
-	fmt.Println(string(b))
-	log.Trace().Str("span-id", s.Id()).Str("har", string(b)).Msg(semLogContext)
 	return nil
 }
 
-func (t *logZeroTracerImpl) Extract(format string, tmr hartracing.TextMapReader) (hartracing.SpanContext, error) {
-
-	var spanContext hartracing.SimpleSpanContext
-	err := tmr.ForeachKey(func(key, val string) error {
-		var err error
-		if strings.ToLower(key) == hartracing.HARTraceIdHeaderName {
-			spanContext, err = hartracing.ExtractSimpleSpanContextFromString(val)
-			return err
-		}
+func (t *logZeroTracerImpl) logEntry(lg *zerolog.Logger, e *har.Entry) {
+	const semLogContext = "log-zero-har-tracer::entry"
 
-		return nil
-	})
+	evt := lg.WithLevel(t.level)
+	if evt == nil {
+		return
+	}
 
-	if spanContext.IsZero() {
-		err = hartracing.ErrSpanContextNotFound
+	for _, a := range t.format.Attrs(e) {
+		switch a.Value.Kind() {
+		case slog.KindString:
+			evt = evt.Str(a.Key, a.Value.String())
+		case slog.KindInt64:
+			evt = evt.Int64(a.Key, a.Value.Int64())
+		case slog.KindFloat64:
+			evt = evt.Float64(a.Key, a.Value.Float64())
+		default:
+			evt = evt.Interface(a.Key, a.Value.Any())
+		}
 	}
 
-	return spanContext, err
+	evt.Msg(semLogContext)
+}
+
+func (t *logZeroTracerImpl) Extract(format string, tmr hartracing.TextMapReader) (hartracing.SpanContext, error) {
+	return hartracing.ExtractSimpleSpanContext(tmr, t.propagationHeaders...)
 }
 
 func (t *logZeroTracerImpl) Inject(s hartracing.SpanContext, tmr hartracing.TextMapWriter) error {
-	tmr.Set(hartracing.HARTraceIdHeaderName, s.Id())
-	return nil
+	return hartracing.InjectSimpleSpanContext(s, tmr, t.propagationHeaders...)
 }
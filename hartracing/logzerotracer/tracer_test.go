@@ -0,0 +1,53 @@
+package logzerotracer_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/har"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/hartracing/logzerotracer"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestStructuredOutput(t *testing.T) {
+
+	var buf bytes.Buffer
+	tracer, c, err := logzerotracer.NewTracer(
+		logzerotracer.WithWriter(&buf),
+		logzerotracer.WithLevel(zerolog.WarnLevel),
+		logzerotracer.WithBodies(true, 8),
+	)
+	require.NoError(t, err)
+	defer c.Close()
+
+	req, err := har.NewRequest(http.MethodPost, "http://localhost:8080/api/v1", []byte(`{"name": "a-long-enough-name"}`), http.Header{"Content-Type": []string{"application/json"}}, nil, nil)
+	require.NoError(t, err)
+
+	s := tracer.StartSpan()
+	require.NoError(t, s.AddEntry(&har.Entry{
+		StartedDateTime: "2023-02-12T20:07:02.147874+01:00",
+		Time:            5,
+		Request:         req,
+		Response:        har.NewResponse(200, "OK", "application/json", []byte(`{"ok": true}`), nil),
+	}))
+	require.NoError(t, s.Finish())
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 1)
+
+	var evt map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &evt))
+	t.Log(lines[0])
+
+	require.Equal(t, "warn", evt["level"])
+	require.Equal(t, http.MethodPost, evt["method"])
+	require.Equal(t, "http://localhost:8080/api/v1", evt["url"])
+	require.Equal(t, float64(200), evt["status"])
+	require.Equal(t, s.Id(), evt["har-trace-id"])
+	require.Equal(t, float64(5), evt["duration-ms"])
+	require.Equal(t, 1, strings.Count(lines[0], `"time":`), "the duration doesn't clash with the timestamp")
+	require.Equal(t, `{"name":`+logzerotracer.TruncatedBodySuffix, evt["req-body"])
+}
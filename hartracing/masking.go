@@ -0,0 +1,132 @@
+package hartracing
+
+import (
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/har"
+	"net/url"
+	"strings"
+)
+
+const (
+	DefaultMaskReplacement = "******"
+)
+
+// MaskingRules lists the header, query parameter and cookie names whose values have to be obfuscated before an entry gets recorded.
+// Names are matched case-insensitively. Masking the Cookie or Set-Cookie header masks all the request or response cookies too.
+type MaskingRules struct {
+	Headers     []string `json:"headers,omitempty" yaml:"headers,omitempty" mapstructure:"headers,omitempty"`                // Request and response headers to mask.
+	Cookies     []string `json:"cookies,omitempty" yaml:"cookies,omitempty" mapstructure:"cookies,omitempty"`                // Cookies to mask both in the cookies and in the Cookie and Set-Cookie headers.
+	QueryParams []string `json:"query-params,omitempty" yaml:"query-params,omitempty" mapstructure:"query-params,omitempty"` // Query parameters to mask both in the queryString and in the url.
+	Replacement string   `json:"replacement,omitempty" yaml:"replacement,omitempty" mapstructure:"replacement,omitempty"`    // Value used in place of the masked one. Defaults to DefaultMaskReplacement.
+}
+
+func (r *MaskingRules) IsZero() bool {
+	return r == nil || (len(r.Headers) == 0 && len(r.QueryParams) == 0 && len(r.Cookies) == 0)
+}
+
+func (r *MaskingRules) replacement() string {
+	if r.Replacement == "" {
+		return DefaultMaskReplacement
+	}
+	return r.Replacement
+}
+
+// Apply masks in place the entry.
+func (r *MaskingRules) Apply(e *har.Entry) {
+	if r.IsZero() || e == nil {
+		return
+	}
+
+	if e.Request != nil {
+		r.maskCookieHeaders(e.Request.Headers, "Cookie")
+		r.maskNameValuePairs(e.Request.Headers, r.Headers)
+		r.maskNameValuePairs(e.Request.QueryString, r.QueryParams)
+		e.Request.URL = r.maskUrl(e.Request.URL)
+		r.maskCookies(e.Request.Cookies, containsFold(r.Headers, "Cookie"))
+	}
+
+	if e.Response != nil {
+		r.maskCookieHeaders(e.Response.Headers, "Set-Cookie")
+		r.maskNameValuePairs(e.Response.Headers, r.Headers)
+		r.maskCookies(e.Response.Cookies, containsFold(r.Headers, "Set-Cookie"))
+	}
+}
+
+// maskCookies masks the cookies listed in the rules or, if all is set, every cookie.
+func (r *MaskingRules) maskCookies(cookies []har.Cookie, all bool) {
+	for i := range cookies {
+		if all || containsFold(r.Cookies, cookies[i].Name) {
+			cookies[i].Value = r.replacement()
+		}
+	}
+}
+
+// maskCookieHeaders masks the values of the cookies listed in the rules within the Cookie (name=value pairs separated by
+// semicolons) or Set-Cookie (leading name=value pair followed by the attributes) headers.
+func (r *MaskingRules) maskCookieHeaders(nvs har.NameValuePairs, headerName string) {
+	if len(r.Cookies) == 0 {
+		return
+	}
+
+	for i := range nvs {
+		if !strings.EqualFold(nvs[i].Name, headerName) {
+			continue
+		}
+
+		parts := strings.Split(nvs[i].Value, ";")
+		for j, part := range parts {
+			if j > 0 && headerName == "Set-Cookie" {
+				break
+			}
+
+			n, _, ok := strings.Cut(part, "=")
+			if ok && containsFold(r.Cookies, strings.TrimSpace(n)) {
+				parts[j] = n + "=" + r.replacement()
+			}
+		}
+		nvs[i].Value = strings.Join(parts, ";")
+	}
+}
+
+func (r *MaskingRules) maskNameValuePairs(nvs har.NameValuePairs, names []string) {
+	for i := range nvs {
+		if containsFold(names, nvs[i].Name) {
+			nvs[i].Value = r.replacement()
+		}
+	}
+}
+
+func (r *MaskingRules) maskUrl(u string) string {
+	if len(r.QueryParams) == 0 || !strings.Contains(u, "?") {
+		return u
+	}
+
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return u
+	}
+
+	q := parsed.Query()
+	masked := false
+	for n := range q {
+		if containsFold(r.QueryParams, n) {
+			q.Set(n, r.replacement())
+			masked = true
+		}
+	}
+
+	if !masked {
+		return u
+	}
+
+	parsed.RawQuery = q.Encode()
+	return parsed.String()
+}
+
+func containsFold(names []string, n string) bool {
+	for _, s := range names {
+		if strings.EqualFold(s, n) {
+			return true
+		}
+	}
+	return false
+}
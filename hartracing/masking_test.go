@@ -0,0 +1,50 @@
+package hartracing_test
+
+import (
+	"encoding/json"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/har"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/hartracing"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMaskingCookies(t *testing.T) {
+	newEntry := func() *har.Entry {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/items", nil)
+		req.Header.Set("Cookie", "session=SECRET; theme=dark")
+		harReq, err := har.NewRequestFromHttpRequest(req)
+		require.NoError(t, err)
+
+		resp := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}}
+		resp.Header.Add("Set-Cookie", "session=SECRET; Path=/; HttpOnly")
+		resp.Header.Add("Set-Cookie", "theme=light; Path=/")
+		harResp, err := har.NewResponseFromHttpResponse(resp)
+		require.NoError(t, err)
+
+		return &har.Entry{Request: harReq, Response: harResp}
+	}
+
+	rules := []*hartracing.MaskingRules{
+		{Headers: []string{"Cookie", "Set-Cookie"}},
+		{Cookies: []string{"session"}},
+	}
+
+	for _, r := range rules {
+		e := newEntry()
+		r.Apply(e)
+
+		b, err := json.Marshal(e)
+		require.NoError(t, err)
+		require.NotContains(t, string(b), "SECRET")
+	}
+
+	// cookie rules leave the other cookies as they are.
+	e := newEntry()
+	rules[1].Apply(e)
+	require.Equal(t, "session=******; theme=dark", e.Request.Headers.GetFirst("Cookie").Value)
+	require.Equal(t, "dark", e.Request.Cookies[1].Value)
+	require.Equal(t, "session=******; Path=/; HttpOnly", e.Response.Headers.GetFirst("Set-Cookie").Value)
+	require.Equal(t, "light", e.Response.Cookies[1].Value)
+}
@@ -0,0 +1,59 @@
+package hartracing
+
+import (
+	"math/rand/v2"
+)
+
+// Sampler decides if a newly started span has to be recorded. The parent is nil for root spans.
+type Sampler interface {
+	IsSampled(parent *SimpleSpanContext) bool
+}
+
+type alwaysSampler struct {
+}
+
+// AlwaysSample records every root span and every child span whose parent hasn't been explicitly discarded upstream.
+// It is the default of the tracers.
+func AlwaysSample() Sampler {
+	return alwaysSampler{}
+}
+
+func (s alwaysSampler) IsSampled(parent *SimpleSpanContext) bool {
+	if parent != nil && parent.Flag != "" {
+		return parent.Sampled()
+	}
+
+	return true
+}
+
+type rateSampler struct {
+	rate float64
+}
+
+// RateSampler records root spans with the given probability (0 < rate <= 1). Child spans follow the decision
+// carried by the parent context so that a trace is either recorded or discarded as a whole.
+// A rate outside the (0, 1) interval samples everything.
+func RateSampler(rate float64) Sampler {
+	if rate <= 0 || rate >= 1 {
+		return AlwaysSample()
+	}
+
+	return rateSampler{rate: rate}
+}
+
+func (s rateSampler) IsSampled(parent *SimpleSpanContext) bool {
+	if parent != nil && parent.Flag != "" {
+		return parent.Sampled()
+	}
+
+	return rand.Float64() < s.rate
+}
+
+// SampledFlag maps the sampling decision to the flag carried by the span context.
+func SampledFlag(sampled bool) string {
+	if sampled {
+		return HARSpanFlagSampled
+	}
+
+	return HARSpanFlagUnSampled
+}
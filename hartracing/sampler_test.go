@@ -0,0 +1,18 @@
+package hartracing_test
+
+import (
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/hartracing"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestAlwaysSampleFollowsParent(t *testing.T) {
+	s := hartracing.AlwaysSample()
+	require.True(t, s.IsSampled(nil))
+	require.True(t, s.IsSampled(&hartracing.SimpleSpanContext{}))
+	require.True(t, s.IsSampled(&hartracing.SimpleSpanContext{Flag: hartracing.HARSpanFlagSampled}))
+	require.False(t, s.IsSampled(&hartracing.SimpleSpanContext{Flag: hartracing.HARSpanFlagUnSampled}))
+
+	child := hartracing.NewSimpleSpanContext(hartracing.SimpleSpanContext{LogId: "l", ParentId: "l", TraceId: "l", Flag: hartracing.HARSpanFlagUnSampled}, nil)
+	require.False(t, child.Sampled())
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/har"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/hartracing/util"
 	"github.com/rs/zerolog/log"
 	"os"
 	"strings"
@@ -50,6 +51,67 @@ func ExtractSimpleSpanContextFromString(ser string) (SimpleSpanContext, error) {
 	return sctx, nil
 }
 
+// ExtractSimpleSpanContext looks up the span context in the carrier. The HARTraceIdHeaderName key is always honored, additional
+// keys can be provided to support different propagation headers.
+func ExtractSimpleSpanContext(tmr TextMapReader, keys ...string) (SimpleSpanContext, error) {
+	var spanContext SimpleSpanContext
+	err := tmr.ForeachKey(func(key, val string) error {
+		var err error
+		key = strings.ToLower(key)
+		if key == HARTraceIdHeaderName || containsFold(keys, key) {
+			spanContext, err = ExtractSimpleSpanContextFromString(val)
+			return err
+		}
+
+		return nil
+	})
+
+	if spanContext.IsZero() {
+		err = ErrSpanContextNotFound
+	}
+
+	return spanContext, err
+}
+
+// InjectSimpleSpanContext writes the span context in the carrier under each of the provided keys (HARTraceIdHeaderName if none).
+func InjectSimpleSpanContext(s SpanContext, tmr TextMapWriter, keys ...string) error {
+	if len(keys) == 0 {
+		keys = []string{HARTraceIdHeaderName}
+	}
+
+	for _, k := range keys {
+		tmr.Set(k, s.Id())
+	}
+	return nil
+}
+
+// NewSimpleSpanContext creates the context of a new span. If a parent is provided the new context inherits its log id
+// and the parent id points to the parent trace id.
+func NewSimpleSpanContext(parent SpanContext, sampler Sampler) SimpleSpanContext {
+	const semLogContext = "simple-span::new-context"
+
+	if sampler == nil {
+		sampler = AlwaysSample()
+	}
+
+	oid := util.NewTraceId()
+	spanCtx := SimpleSpanContext{LogId: oid, ParentId: oid, TraceId: oid}
+
+	var parentCtx *SimpleSpanContext
+	if parent != nil {
+		if ctxImpl, ok := parent.(SimpleSpanContext); ok {
+			spanCtx.LogId = ctxImpl.LogId
+			spanCtx.ParentId = ctxImpl.TraceId
+			parentCtx = &ctxImpl
+		} else {
+			log.Warn().Msg(semLogContext + " unsupported implementation: wanted internal.spanContextImpl")
+		}
+	}
+
+	spanCtx.Flag = SampledFlag(sampler.IsSampled(parentCtx))
+	return spanCtx
+}
+
 type SimpleSpan struct {
 	Tracer      Tracer
 	SpanContext SimpleSpanContext
@@ -60,6 +122,8 @@ type SimpleSpan struct {
 	Duration    time.Duration
 	Finished    bool
 	Entries     []*har.Entry
+	Masking     *MaskingRules
+	Version     string // har version of the output, har.DefaultVersion if empty.
 }
 
 func (hs *SimpleSpan) Finish() error {
@@ -86,6 +150,7 @@ func (hs *SimpleSpan) String() string {
 
 func (hs *SimpleSpan) AddEntry(e *har.Entry) error {
 	e.TraceId = hs.Id()
+	hs.Masking.Apply(e)
 	hs.Entries = append(hs.Entries, e)
 	return nil
 }
@@ -99,21 +164,36 @@ func (hs *SimpleSpan) GetHARData() (*har.HAR, error) {
 		podName = "localhost"
 	}
 
+	// the conversion works on copies not to change the span.
+	browser := hs.Browser
 	har := har.HAR{
 		Log: &har.Log{
-			Version: "1.1",
+			Version: har.DefaultVersion,
 			Creator: &har.Creator{
 				Name:    "tpm-har",
 				Version: "1.0",
 			},
-			Browser: &hs.Browser,
+			Browser: &browser,
 			Comment: hs.Comment,
 			TraceId: hs.Id(),
 		},
 	}
 
 	for _, e := range hs.Entries {
-		har.Log.Entries = append(har.Log.Entries, e)
+		har.Log.Entries = append(har.Log.Entries, e.Clone())
+	}
+
+	// the entries get the mandatory objects and values of the model version and, if required, are converted to the output one.
+	version := hs.Version
+	if version == "" {
+		version = har.Log.Version
+	}
+
+	for _, v := range []string{har.Log.Version, version} {
+		if err := har.ConvertTo(v); err != nil {
+			log.Error().Err(err).Msg(semLogContext)
+			return nil, err
+		}
 	}
 
 	return &har, nil
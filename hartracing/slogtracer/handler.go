@@ -0,0 +1,76 @@
+package slogtracer
+
+import (
+	"context"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/hartracing"
+	"log/slog"
+)
+
+const (
+	TraceIdAttrName = hartracing.TraceIdLogAttrName
+	LogIdAttrName   = "har-log-id"
+)
+
+// ContextHandler wraps a slog.Handler adding the har trace id and log id of the span carried by the context
+// (see hartracing.SpanFromContext) to each record. Records whose context doesn't carry a span are passed as they are.
+// The span attributes are always added at the top level, even if the handler has been derived with WithGroup.
+type ContextHandler struct {
+	base slog.Handler
+	next slog.Handler
+	ops  []func(slog.Handler) slog.Handler // WithAttrs and WithGroup calls applied to base to get next.
+}
+
+func NewContextHandler(next slog.Handler) *ContextHandler {
+	return &ContextHandler{base: next, next: next}
+}
+
+func (h *ContextHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	return h.next.Enabled(ctx, l)
+}
+
+func (h *ContextHandler) Handle(ctx context.Context, r slog.Record) error {
+	attrs := SpanAttrs(ctx)
+	if len(attrs) == 0 {
+		return h.next.Handle(ctx, r)
+	}
+
+	next := h.base.WithAttrs(attrs)
+	for _, op := range h.ops {
+		next = op(next)
+	}
+
+	return next.Handle(ctx, r)
+}
+
+func (h *ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return h.with(func(next slog.Handler) slog.Handler { return next.WithAttrs(attrs) })
+}
+
+func (h *ContextHandler) WithGroup(name string) slog.Handler {
+	return h.with(func(next slog.Handler) slog.Handler { return next.WithGroup(name) })
+}
+
+func (h *ContextHandler) with(op func(slog.Handler) slog.Handler) *ContextHandler {
+	ops := make([]func(slog.Handler) slog.Handler, len(h.ops), len(h.ops)+1)
+	copy(ops, h.ops)
+	return &ContextHandler{base: h.base, next: op(h.next), ops: append(ops, op)}
+}
+
+// SpanAttrs returns the attributes identifying the span carried by the context, if any.
+func SpanAttrs(ctx context.Context) []slog.Attr {
+	if ctx == nil {
+		return nil
+	}
+
+	span := hartracing.SpanFromContext(ctx)
+	if span == nil {
+		return nil
+	}
+
+	attrs := []slog.Attr{slog.String(TraceIdAttrName, span.Id())}
+	if sctx, ok := span.Context().(hartracing.SimpleSpanContext); ok {
+		attrs = append(attrs, slog.String(LogIdAttrName, sctx.LogId))
+	}
+
+	return attrs
+}
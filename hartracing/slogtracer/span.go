@@ -0,0 +1,31 @@
+package slogtracer
+
+import (
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/hartracing"
+	"github.com/rs/zerolog/log"
+	"time"
+)
+
+type slogSpanImpl struct {
+	hartracing.SimpleSpan
+}
+
+func (hs *slogSpanImpl) Finish() error {
+
+	const semLogContext = "slog-har-tracer::finish-span"
+
+	hs.Duration = time.Since(hs.StartTime)
+	if !hs.Sampled() {
+		log.Trace().Str("span-id", hs.Id()).Msg(semLogContext + " span not sampled....")
+		return nil
+	}
+
+	if len(hs.Entries) > 0 {
+		log.Trace().Str("span-id", hs.Id()).Msg(semLogContext + " reporting span")
+		_ = hs.Tracer.(*slogTracerImpl).Report(hs)
+	} else {
+		log.Trace().Str("span-id", hs.Id()).Msg(semLogContext + " no Entries in span....")
+	}
+
+	return nil
+}
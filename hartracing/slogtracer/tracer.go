@@ -0,0 +1,172 @@
+package slogtracer
+
+import (
+	"context"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/har"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/hartracing"
+	"github.com/rs/zerolog/log"
+	"io"
+	"log/slog"
+	"time"
+)
+
+const (
+	HarSlogTracerType   = "har-slog-tracer"
+	DefaultMaxBodySize  = hartracing.DefaultMaxLogBodySize
+	TruncatedBodySuffix = hartracing.TruncatedBodySuffix
+)
+
+type slogTracerImpl struct {
+	sampler            hartracing.Sampler
+	masking            *hartracing.MaskingRules
+	propagationHeaders []string
+	level              slog.Level
+	logger             *slog.Logger
+	format             hartracing.EntryLogFormat
+}
+
+type tracerOpts struct {
+	sampler            hartracing.Sampler
+	masking            *hartracing.MaskingRules
+	propagationHeaders []string
+	level              slog.Level
+	logger             *slog.Logger
+	format             hartracing.EntryLogFormat
+}
+
+type Option func(opts *tracerOpts)
+
+func WithSampler(s hartracing.Sampler) Option {
+	return func(opts *tracerOpts) {
+		opts.sampler = s
+	}
+}
+
+func WithMasking(m *hartracing.MaskingRules) Option {
+	return func(opts *tracerOpts) {
+		opts.masking = m
+	}
+}
+
+// WithPropagationHeaders sets the carrier keys used to extract and inject the span context.
+func WithPropagationHeaders(h ...string) Option {
+	return func(opts *tracerOpts) {
+		opts.propagationHeaders = h
+	}
+}
+
+// WithLevel sets the level of the records emitted for each entry. Defaults to info.
+func WithLevel(l slog.Level) Option {
+	return func(opts *tracerOpts) {
+		opts.level = l
+	}
+}
+
+// WithLogger sets the logger used to emit the entries. Defaults to slog.Default().
+func WithLogger(l *slog.Logger) Option {
+	return func(opts *tracerOpts) {
+		opts.logger = l
+	}
+}
+
+// WithWriter emits the entries as json lines on the provided writer.
+func WithWriter(w io.Writer) Option {
+	return func(opts *tracerOpts) {
+		opts.logger = slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	}
+}
+
+// WithBodies includes the request and response bodies in the records, truncated to maxBodySize bytes (DefaultMaxBodySize if <= 0).
+func WithBodies(include bool, maxBodySize int) Option {
+	return func(opts *tracerOpts) {
+		opts.format = hartracing.EntryLogFormat{IncludeBodies: include, MaxBodySize: maxBodySize}
+	}
+}
+
+func NewTracer(opts ...Option) (hartracing.Tracer, io.Closer, error) {
+
+	trcOpts := tracerOpts{level: slog.LevelInfo}
+	for _, o := range opts {
+		o(&trcOpts)
+	}
+
+	if trcOpts.sampler == nil {
+		trcOpts.sampler = hartracing.AlwaysSample()
+	}
+
+	t := &slogTracerImpl{
+		sampler:            trcOpts.sampler,
+		masking:            trcOpts.masking,
+		propagationHeaders: trcOpts.propagationHeaders,
+		level:              trcOpts.level,
+		logger:             trcOpts.logger,
+		format:             trcOpts.format,
+	}
+	return t, t, nil
+}
+
+func (t *slogTracerImpl) Close() error {
+	return nil
+}
+
+func (t *slogTracerImpl) IsNil() bool {
+	return false
+}
+
+func (t *slogTracerImpl) StartSpan(opts ...hartracing.SpanOption) hartracing.Span {
+	spanOpts := hartracing.SpanOptions{}
+	for _, o := range opts {
+		o(&spanOpts)
+	}
+
+	span := slogSpanImpl{
+		hartracing.SimpleSpan{
+			Tracer:      t,
+			SpanContext: hartracing.NewSimpleSpanContext(spanOpts.ParentContext, t.sampler),
+			StartTime:   time.Now(),
+			Masking:     t.masking,
+		},
+	}
+
+	return &span
+}
+
+func (t *slogTracerImpl) Report(s *slogSpanImpl) error {
+	const semLogContext = "slog-har-tracer::report"
+
+	h, err := s.GetHARData()
+	if err != nil {
+		log.Error().Err(err).Msg(semLogContext)
+		return err
+	}
+
+	lg := t.logger
+	if lg == nil {
+		lg = slog.Default()
+	}
+
+	for _, e := range h.Log.Entries {
+		t.logEntry(lg, e)
+	}
+
+	return nil
+}
+
+func (t *slogTracerImpl) logEntry(lg *slog.Logger, e *har.Entry) {
+	const semLogContext = "slog-har-tracer::entry"
+
+	ctx := context.Background()
+	if !lg.Enabled(ctx, t.level) {
+		return
+	}
+
+	lg.LogAttrs(ctx, t.level, semLogContext, t.format.Attrs(e)...)
+}
+
+func (t *slogTracerImpl) Extract(format string, tmr hartracing.TextMapReader) (hartracing.SpanContext, error) {
+	return hartracing.ExtractSimpleSpanContext(tmr, t.propagationHeaders...)
+}
+
+func (t *slogTracerImpl) Inject(s hartracing.SpanContext, tmr hartracing.TextMapWriter) error {
+	return hartracing.InjectSimpleSpanContext(s, tmr, t.propagationHeaders...)
+}
@@ -0,0 +1,83 @@
+package slogtracer_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/har"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/hartracing"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/hartracing/slogtracer"
+	"github.com/stretchr/testify/require"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestSlogTracer(t *testing.T) {
+
+	var buf bytes.Buffer
+	tracer, c, err := slogtracer.NewTracer(slogtracer.WithWriter(&buf), slogtracer.WithBodies(true, 0))
+	require.NoError(t, err)
+	defer c.Close()
+
+	s := tracer.StartSpan()
+	require.NoError(t, s.AddEntry(&har.Entry{
+		StartedDateTime: "2023-02-12T20:07:02.147874+01:00",
+		Time:            3,
+		Request:         &har.Request{Method: http.MethodGet, URL: "http://localhost:3004/example-01", BodySize: -1},
+		Response:        har.NewResponse(503, "Service Unavailable", "application/json", []byte(`{"ambit":"endpoint01"}`), nil),
+	}))
+	require.NoError(t, s.Finish())
+
+	var rec map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &rec))
+	require.Equal(t, s.Id(), rec[slogtracer.TraceIdAttrName])
+	require.Equal(t, http.MethodGet, rec["method"])
+	require.Equal(t, float64(503), rec["status"])
+	require.Equal(t, `{"ambit":"endpoint01"}`, rec["resp-body"])
+	require.Equal(t, float64(3), rec["duration-ms"])
+	require.IsType(t, "", rec["time"], "the duration doesn't clash with the timestamp")
+	require.Equal(t, 1, strings.Count(buf.String(), `"time":`))
+}
+
+func TestContextHandler(t *testing.T) {
+
+	var buf bytes.Buffer
+	lg := slog.New(slogtracer.NewContextHandler(slog.NewJSONHandler(&buf, nil)))
+
+	tracer, _, _ := slogtracer.NewTracer()
+	s := tracer.StartSpan()
+	ctx := hartracing.ContextWithSpan(context.Background(), s)
+
+	lg.InfoContext(ctx, "with span")
+	lg.InfoContext(context.Background(), "without span")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+
+	var rec map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &rec))
+	require.Equal(t, s.Id(), rec[slogtracer.TraceIdAttrName])
+	require.Equal(t, s.Context().(hartracing.SimpleSpanContext).LogId, rec[slogtracer.LogIdAttrName])
+
+	rec = nil
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &rec))
+	require.NotContains(t, rec, slogtracer.TraceIdAttrName)
+}
+
+func TestContextHandlerWithGroup(t *testing.T) {
+
+	var buf bytes.Buffer
+	lg := slog.New(slogtracer.NewContextHandler(slog.NewJSONHandler(&buf, nil))).With("svc", "api").WithGroup("req")
+
+	tracer, _, _ := slogtracer.NewTracer()
+	s := tracer.StartSpan()
+	lg.InfoContext(hartracing.ContextWithSpan(context.Background(), s), "with span", "id", 1)
+
+	var rec map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &rec))
+	require.Equal(t, s.Id(), rec[slogtracer.TraceIdAttrName])
+	require.Equal(t, "api", rec["svc"])
+	require.Equal(t, map[string]interface{}{"id": float64(1)}, rec["req"])
+}
@@ -233,3 +233,46 @@ func TestFileTracer(t *testing.T) {
 
 	time.Sleep(10 * time.Second)
 }
+
+func TestSimpleSpanVersion(t *testing.T) {
+	newSpan := func(version string) *hartracing.SimpleSpan {
+		return &hartracing.SimpleSpan{
+			SpanContext: hartracing.SimpleSpanContext{LogId: "log", ParentId: "parent", TraceId: "trace", Flag: "1"},
+			Version:     version,
+			Entries: []*har.Entry{{
+				StartedDateTime: "2023-02-12T20:07:02.147874+01:00",
+				Time:            5,
+				Request:         &har.Request{Method: http.MethodGet, URL: "http://localhost:3004/example-01", BodySize: -1},
+				Response:        har.NewResponse(200, "OK", "application/json", nil, nil),
+			}},
+		}
+	}
+
+	h, err := newSpan("").GetHARData()
+	require.NoError(t, err)
+	require.Equal(t, har.Version12, h.Log.Version)
+	require.NotNil(t, h.Log.Entries[0].Cache)
+	require.NotNil(t, h.Log.Entries[0].Timings)
+	require.Equal(t, float64(-1), h.Log.Entries[0].Timings.Ssl)
+
+	h, err = newSpan(har.Version11).GetHARData()
+	require.NoError(t, err)
+	require.Equal(t, har.Version11, h.Log.Version)
+	require.Zero(t, h.Log.Entries[0].Timings.Ssl)
+
+	_, err = newSpan("0.9").GetHARData()
+	require.Error(t, err)
+
+	s := newSpan(har.Version11)
+	s.Browser = har.Creator{Name: "browser", Comment: "browser comment"}
+	s.Entries[0].Pageref = "page_1"
+	s.Entries[0].Comment = "entry comment"
+	s.Entries[0].Request.Comment = "request comment"
+	_, err = s.GetHARData()
+	require.NoError(t, err)
+	require.Equal(t, "browser comment", s.Browser.Comment, "the span is not changed")
+	require.Equal(t, "page_1", s.Entries[0].Pageref)
+	require.Equal(t, "entry comment", s.Entries[0].Comment)
+	require.Equal(t, "request comment", s.Entries[0].Request.Comment)
+	require.Nil(t, s.Entries[0].Timings)
+}
@@ -0,0 +1,178 @@
+package harvcr
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/har"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Matcher tells if the cassette entry answers the outbound request.
+type Matcher func(req *http.Request, body []byte, e *har.Entry) bool
+
+// Normalizer rewrites a value before comparison, e.g. to blank out timestamps and nonces.
+type Normalizer func(s string) string
+
+// RegexpNormalizer replaces the matches of the pattern with the replacement.
+func RegexpNormalizer(pattern string, replacement string) Normalizer {
+	re := regexp.MustCompile(pattern)
+	return func(s string) string {
+		return re.ReplaceAllString(s, replacement)
+	}
+}
+
+type matcherOpts struct {
+	matchBody         bool
+	matchHeaders      []string
+	ignoreQueryParams []string
+	ignoreJsonFields  []string
+	urlNormalizers    []Normalizer
+	headerNormalizers []Normalizer
+	bodyNormalizers   []Normalizer
+}
+
+type MatcherOption func(o *matcherOpts)
+
+// MatchBody compares the request bodies. Json bodies are compared structurally.
+func MatchBody(b bool) MatcherOption {
+	return func(o *matcherOpts) {
+		o.matchBody = b
+	}
+}
+
+// MatchHeaders compares the values of the listed headers.
+func MatchHeaders(names ...string) MatcherOption {
+	return func(o *matcherOpts) {
+		o.matchHeaders = append(o.matchHeaders, names...)
+	}
+}
+
+// IgnoreQueryParams excludes the listed query parameters from the comparison of the urls.
+func IgnoreQueryParams(names ...string) MatcherOption {
+	return func(o *matcherOpts) {
+		o.ignoreQueryParams = append(o.ignoreQueryParams, names...)
+	}
+}
+
+// IgnoreJsonFields excludes the listed top level and nested fields (dot separated paths) from the comparison of json bodies.
+func IgnoreJsonFields(paths ...string) MatcherOption {
+	return func(o *matcherOpts) {
+		o.ignoreJsonFields = append(o.ignoreJsonFields, paths...)
+	}
+}
+
+func WithUrlNormalizer(n Normalizer) MatcherOption {
+	return func(o *matcherOpts) {
+		o.urlNormalizers = append(o.urlNormalizers, n)
+	}
+}
+
+func WithHeaderNormalizer(n Normalizer) MatcherOption {
+	return func(o *matcherOpts) {
+		o.headerNormalizers = append(o.headerNormalizers, n)
+	}
+}
+
+func WithBodyNormalizer(n Normalizer) MatcherOption {
+	return func(o *matcherOpts) {
+		o.bodyNormalizers = append(o.bodyNormalizers, n)
+	}
+}
+
+// NewMatcher builds a matcher comparing method and url (query parameters in any order) and, optionally, headers and body.
+func NewMatcher(opts ...MatcherOption) Matcher {
+	o := matcherOpts{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return func(req *http.Request, body []byte, e *har.Entry) bool {
+		if !strings.EqualFold(req.Method, e.Request.Method) {
+			return false
+		}
+
+		recorded, err := url.Parse(e.Request.URL)
+		if err != nil || o.canonicalUrl(req.URL) != o.canonicalUrl(recorded) {
+			return false
+		}
+
+		for _, n := range o.matchHeaders {
+			if o.normalize(o.headerNormalizers, req.Header.Get(n)) != o.normalize(o.headerNormalizers, e.Request.Headers.GetFirst(n).Value) {
+				return false
+			}
+		}
+
+		if o.matchBody {
+			var recordedBody []byte
+			if e.Request.PostData != nil {
+				recordedBody = e.Request.PostData.Body()
+			}
+			if o.canonicalBody(body) != o.canonicalBody(recordedBody) {
+				return false
+			}
+		}
+
+		return true
+	}
+}
+
+func (o *matcherOpts) normalize(ns []Normalizer, s string) string {
+	for _, n := range ns {
+		s = n(s)
+	}
+	return s
+}
+
+func (o *matcherOpts) canonicalUrl(u *url.URL) string {
+	q := u.Query()
+	for _, n := range o.ignoreQueryParams {
+		q.Del(n)
+	}
+	for _, vs := range q {
+		sort.Strings(vs)
+	}
+
+	c := *u
+	c.RawQuery = q.Encode()
+	c.Fragment = ""
+	if c.Path == "" {
+		c.Path = "/"
+	}
+	return o.normalize(o.urlNormalizers, c.String())
+}
+
+func (o *matcherOpts) canonicalBody(b []byte) string {
+	b = bytes.TrimSpace(b)
+	if len(b) > 0 && (b[0] == '{' || b[0] == '[') {
+		var v interface{}
+		if err := json.Unmarshal(b, &v); err == nil {
+			for _, p := range o.ignoreJsonFields {
+				deleteJsonPath(v, strings.Split(p, "."))
+			}
+			// map keys get sorted by the encoder.
+			if cb, err := json.Marshal(v); err == nil {
+				b = cb
+			}
+		}
+	}
+	return o.normalize(o.bodyNormalizers, string(b))
+}
+
+func deleteJsonPath(v interface{}, path []string) {
+	switch tv := v.(type) {
+	case map[string]interface{}:
+		if len(path) == 1 {
+			delete(tv, path[0])
+			return
+		}
+		deleteJsonPath(tv[path[0]], path[1:])
+	case []interface{}:
+		for _, item := range tv {
+			deleteJsonPath(item, path)
+		}
+	}
+}
@@ -0,0 +1,317 @@
+package harvcr
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/har"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/hartracing/util"
+	"github.com/rs/zerolog/log"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Mode selects how the recorder deals with outbound requests.
+type Mode int
+
+const (
+	// ModeReplay serves the requests from the cassette only, unmatched requests fail with ErrNoMatch.
+	ModeReplay Mode = iota
+	// ModeRecord sends every request to the real service and records the exchanges in a fresh cassette.
+	ModeRecord
+	// ModeReplayOrRecord serves the requests found in the cassette and sends, recording them, the missing ones.
+	ModeReplayOrRecord
+)
+
+var ErrNoMatch = errors.New("harvcr: no cassette entry matches the request")
+
+// Recorder is an http.RoundTripper backed by a har cassette.
+type Recorder struct {
+	mu            sync.Mutex
+	mode          Mode
+	cassettePath  string
+	cassette      *har.HAR
+	next          http.RoundTripper
+	matcher       Matcher
+	redactHeaders []string
+	used          map[*har.Entry]bool
+	modified      bool
+}
+
+type Option func(r *Recorder)
+
+func WithMode(m Mode) Option {
+	return func(r *Recorder) {
+		r.mode = m
+	}
+}
+
+// WithTransport sets the round tripper used to reach the real services. Defaults to http.DefaultTransport.
+func WithTransport(t http.RoundTripper) Option {
+	return func(r *Recorder) {
+		r.next = t
+	}
+}
+
+// WithMatcher replaces the matcher used to look up the cassette. Defaults to NewMatcher().
+func WithMatcher(m Matcher) Option {
+	return func(r *Recorder) {
+		r.matcher = m
+	}
+}
+
+// WithRedactedHeaders replaces with a placeholder the value of the listed headers before the exchange is recorded.
+// Redacting the Cookie or Set-Cookie header redacts the request or response cookies too.
+func WithRedactedHeaders(names ...string) Option {
+	return func(r *Recorder) {
+		r.redactHeaders = append(r.redactHeaders, names...)
+	}
+}
+
+const RedactedValue = "[redacted]"
+
+// New creates a recorder on the cassette file. In replay modes the file is loaded if present (ModeReplay requires it),
+// in record mode it gets overwritten on Save.
+func New(cassettePath string, opts ...Option) (*Recorder, error) {
+	const semLogContext = "har-vcr::new"
+
+	r := &Recorder{mode: ModeReplay, cassettePath: cassettePath, used: map[*har.Entry]bool{}}
+	for _, o := range opts {
+		o(r)
+	}
+
+	if r.next == nil {
+		r.next = http.DefaultTransport
+	}
+
+	if r.matcher == nil {
+		r.matcher = NewMatcher()
+	}
+
+	switch {
+	case r.mode != ModeRecord && util.FileExists(cassettePath):
+		h, err := har.LoadFile(cassettePath)
+		if err != nil {
+			log.Error().Err(err).Str("cassette", cassettePath).Msg(semLogContext)
+			return nil, err
+		}
+		r.cassette = h
+	case r.mode == ModeReplay:
+		err := fmt.Errorf("harvcr: cassette %s not found", cassettePath)
+		log.Error().Err(err).Msg(semLogContext)
+		return nil, err
+	default:
+		r.cassette = har.NewHAR(har.WithCreator("tpm-har-vcr", "1.0"))
+	}
+
+	return r, nil
+}
+
+// Client returns an http client using the recorder as transport.
+func (r *Recorder) Client() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// Cassette returns the har backing the recorder.
+func (r *Recorder) Cassette() *har.HAR {
+	return r.cassette
+}
+
+// Save writes the cassette if new exchanges have been recorded.
+func (r *Recorder) Save() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.modified {
+		return nil
+	}
+
+	if err := r.cassette.WriteFile(r.cassettePath, true); err != nil {
+		return err
+	}
+
+	r.modified = false
+	return nil
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	const semLogContext = "har-vcr::round-trip"
+
+	// the recorder works on a copy not to change the request of the caller.
+	req, body, err := cloneRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.mode != ModeRecord {
+		if e := r.lookup(req, body); e != nil {
+			log.Trace().Str("method", req.Method).Str("url", req.URL.String()).Msg(semLogContext + " replaying")
+			return newHttpResponse(req, e.Response), nil
+		}
+
+		if r.mode == ModeReplay {
+			return nil, fmt.Errorf("%w: %s %s", ErrNoMatch, req.Method, req.URL.String())
+		}
+	}
+
+	log.Trace().Str("method", req.Method).Str("url", req.URL.String()).Msg(semLogContext + " recording")
+	return r.record(req, body)
+}
+
+func (r *Recorder) lookup(req *http.Request, body []byte) *har.Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var last *har.Entry
+	for _, e := range r.cassette.Log.Entries {
+		if e.Request == nil || e.Response == nil || !r.matcher(req, body, e) {
+			continue
+		}
+
+		if !r.used[e] {
+			r.used[e] = true
+			return e
+		}
+		last = e
+	}
+
+	// all the matching entries have been served: keep on serving the last one.
+	return last
+}
+
+func (r *Recorder) record(req *http.Request, body []byte) (*http.Response, error) {
+	start := time.Now()
+	harReq, err := har.NewRequestFromHttpRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	harResp, err := har.NewResponseFromHttpResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	elapsed := float64(time.Since(start).Milliseconds())
+	e := &har.Entry{
+		StartedDateTime: start.Format(time.RFC3339Nano),
+		StartDateTimeTm: start,
+		Time:            elapsed,
+		Request:         harReq,
+		Response:        harResp,
+		Timings:         &har.Timings{Blocked: -1, DNS: -1, Connect: -1, Ssl: -1, Wait: elapsed},
+	}
+	r.redact(e)
+	encodeBinaryContent(e.Response.Content)
+
+	r.mu.Lock()
+	r.cassette.Log.Entries = append(r.cassette.Log.Entries, e)
+	r.used[e] = true
+	r.modified = true
+	r.mu.Unlock()
+
+	return resp, nil
+}
+
+func (r *Recorder) redact(e *har.Entry) {
+	for _, n := range r.redactHeaders {
+		for i := range e.Request.Headers {
+			if strings.EqualFold(e.Request.Headers[i].Name, n) {
+				e.Request.Headers[i].Value = RedactedValue
+			}
+		}
+		for i := range e.Response.Headers {
+			if strings.EqualFold(e.Response.Headers[i].Name, n) {
+				e.Response.Headers[i].Value = RedactedValue
+			}
+		}
+
+		// the cookies parsed from a redacted header would carry its values.
+		switch {
+		case strings.EqualFold(n, "Cookie"):
+			redactCookies(e.Request.Cookies)
+		case strings.EqualFold(n, "Set-Cookie"):
+			redactCookies(e.Response.Cookies)
+		}
+	}
+}
+
+func redactCookies(cookies []har.Cookie) {
+	for i := range cookies {
+		cookies[i].Value = RedactedValue
+	}
+}
+
+// encodeBinaryContent stores the bodies that are not valid utf-8 as base64 text: a json string would not carry them unaltered.
+func encodeBinaryContent(c *har.Content) {
+	if c == nil || len(c.Data) == 0 || utf8.Valid(c.Data) {
+		return
+	}
+
+	c.Text = base64.StdEncoding.EncodeToString(c.Data)
+	c.Encoding = "base64"
+	c.Data = nil
+}
+
+func cloneRequest(req *http.Request) (*http.Request, []byte, error) {
+	clone := req.Clone(req.Context())
+	if req.Body == nil || req.Body == http.NoBody {
+		return clone, nil, nil
+	}
+
+	b, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	clone.Body = io.NopCloser(bytes.NewReader(b))
+	return clone, b, nil
+}
+
+func newHttpResponse(req *http.Request, resp *har.Response) *http.Response {
+	var body []byte
+	if resp.Content != nil {
+		body = resp.Content.Body()
+	}
+
+	decoded := resp.HasDecodedContent()
+	h := http.Header{}
+	for _, nv := range resp.Headers {
+		switch n := strings.ToLower(nv.Name); {
+		case n == "content-length", n == "transfer-encoding":
+		case n == "content-encoding" && decoded:
+			// the content is served as recorded, already decoded.
+		default:
+			h.Add(nv.Name, nv.Value)
+		}
+	}
+	h.Set("Content-Length", strconv.Itoa(len(body)))
+
+	status := resp.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        h,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
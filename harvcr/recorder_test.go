@@ -0,0 +1,188 @@
+package harvcr_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/har"
+	"github.com/GPA-Gruppo-Progetti-Avanzati-SRL/tpm-http-archive/harvcr"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func call(t *testing.T, c *http.Client, u string, body string) (int, string, error) {
+	resp, err := c.Post(u, "application/json", strings.NewReader(body))
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, string(b), nil
+}
+
+func TestRecordAndReplay(t *testing.T) {
+
+	hits := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		b, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"echo":%s,"hit":%d}`, string(b), hits)
+	}))
+	defer srv.Close()
+
+	cassette := filepath.Join(t.TempDir(), "cassette.har")
+	matcher := harvcr.NewMatcher(
+		harvcr.MatchBody(true),
+		harvcr.IgnoreJsonFields("timestamp"),
+		harvcr.IgnoreQueryParams("nonce"),
+	)
+
+	rec, err := harvcr.New(cassette, harvcr.WithMode(harvcr.ModeRecord), harvcr.WithMatcher(matcher), harvcr.WithRedactedHeaders("Authorization"))
+	require.NoError(t, err)
+	sc, body, err := call(t, rec.Client(), srv.URL+"/api/v1/items?nonce=1", fmt.Sprintf(`{"id":1,"timestamp":%d}`, time.Now().UnixNano()))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, sc)
+	require.Contains(t, body, `"hit":1`)
+	require.NoError(t, rec.Save())
+
+	// offline: the server is not hit anymore and volatile fields are ignored.
+	rep, err := harvcr.New(cassette, harvcr.WithMode(harvcr.ModeReplay), harvcr.WithMatcher(matcher))
+	require.NoError(t, err)
+	sc, body, err = call(t, rep.Client(), srv.URL+"/api/v1/items?nonce=2", fmt.Sprintf(`{"timestamp":%d, "id":1}`, time.Now().UnixNano()))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, sc)
+	require.Contains(t, body, `"hit":1`)
+	require.Equal(t, 1, hits)
+
+	_, _, err = call(t, rep.Client(), srv.URL+"/api/v1/items", `{"id":2}`)
+	require.True(t, errors.Is(err, harvcr.ErrNoMatch))
+
+	// missing entries get recorded.
+	mixed, err := harvcr.New(cassette, harvcr.WithMode(harvcr.ModeReplayOrRecord), harvcr.WithMatcher(matcher))
+	require.NoError(t, err)
+	_, body, err = call(t, mixed.Client(), srv.URL+"/api/v1/items", `{"id":2}`)
+	require.NoError(t, err)
+	require.Contains(t, body, `"hit":2`)
+	require.NoError(t, mixed.Save())
+	require.Len(t, mixed.Cassette().Log.Entries, 2)
+}
+
+func TestRecordAndReplayBinary(t *testing.T) {
+
+	payload := []byte{0x89, 'P', 'N', 'G', 0x0d, 0x0a, 0x1a, 0x0a, 0x00, 0xff, 0xfe, 0x80}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write(payload)
+	}))
+	defer srv.Close()
+
+	cassette := filepath.Join(t.TempDir(), "cassette.har")
+	rec, err := harvcr.New(cassette, harvcr.WithMode(harvcr.ModeRecord))
+	require.NoError(t, err)
+	resp, err := rec.Client().Get(srv.URL + "/logo.png")
+	require.NoError(t, err)
+	b, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, payload, b)
+	require.NoError(t, rec.Save())
+
+	rep, err := harvcr.New(cassette, harvcr.WithMode(harvcr.ModeReplay))
+	require.NoError(t, err)
+	require.Equal(t, "base64", rep.Cassette().Log.Entries[0].Response.Content.Encoding)
+
+	resp, err = rep.Client().Get(srv.URL + "/logo.png")
+	require.NoError(t, err)
+	b, err = io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.True(t, bytes.Equal(payload, b), "the binary body is replayed unaltered")
+}
+
+func TestRecordRedactedCookies(t *testing.T) {
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "SECRET-RESP", Path: "/"})
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	cassette := filepath.Join(t.TempDir(), "cassette.har")
+	rec, err := harvcr.New(cassette, harvcr.WithMode(harvcr.ModeRecord), harvcr.WithRedactedHeaders("Cookie", "Set-Cookie"))
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/api", nil)
+	require.NoError(t, err)
+	req.AddCookie(&http.Cookie{Name: "session", Value: "SECRET-REQ"})
+	resp, err := rec.Client().Do(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	require.NoError(t, rec.Save())
+
+	b, err := os.ReadFile(cassette)
+	require.NoError(t, err)
+	require.NotContains(t, string(b), "SECRET")
+	require.Contains(t, string(b), harvcr.RedactedValue)
+}
+
+func TestReplayDecodedContent(t *testing.T) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, _ = zw.Write([]byte(`{"id":1,"v":"decoded"}`))
+	require.NoError(t, zw.Close())
+
+	httpResp := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(bytes.NewReader(buf.Bytes()))}
+	httpResp.Header.Set("Content-Type", "application/json")
+	httpResp.Header.Set("Content-Encoding", "gzip")
+	resp, err := har.NewResponseFromHttpResponse(httpResp, har.WithContentDecoding(true))
+	require.NoError(t, err)
+
+	req, err := har.NewRequest(http.MethodGet, "http://backend/api/v1/items/1", nil, http.Header{}, nil, nil)
+	require.NoError(t, err)
+
+	cassette := filepath.Join(t.TempDir(), "cassette.har")
+	require.NoError(t, har.NewHAR(har.WithEntry(&har.Entry{Request: req, Response: resp})).WriteFile(cassette, false))
+
+	rep, err := harvcr.New(cassette, harvcr.WithMode(harvcr.ModeReplay))
+	require.NoError(t, err)
+	r, err := rep.Client().Get("http://backend/api/v1/items/1")
+	require.NoError(t, err)
+	defer r.Body.Close()
+
+	b, err := io.ReadAll(r.Body)
+	require.NoError(t, err)
+	require.Empty(t, r.Header.Get("Content-Encoding"))
+	require.Equal(t, `{"id":1,"v":"decoded"}`, string(b))
+}
+
+func TestRoundTripKeepsCallerRequest(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(w, r.Body)
+	}))
+	defer srv.Close()
+
+	rec, err := harvcr.New(filepath.Join(t.TempDir(), "cassette.har"), harvcr.WithMode(harvcr.ModeRecord))
+	require.NoError(t, err)
+
+	body := io.NopCloser(strings.NewReader(`{"id":1}`))
+	req, err := http.NewRequest(http.MethodPost, srv.URL+"/api/v1/items", body)
+	require.NoError(t, err)
+
+	resp, err := rec.RoundTrip(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, `{"id":1}`, string(b))
+	require.True(t, req.Body == body, "the body of the caller's request is not replaced")
+}